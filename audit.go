@@ -0,0 +1,201 @@
+package filespot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultGCIssues are deleted by AuditLinks in GC mode when AuditOptions.GCIssues is empty
+var defaultGCIssues = []LinkIssue{LinkMissingObject, LinkExpired}
+
+// LinkIssue is a problem of temporary Link found by AuditLinks
+type LinkIssue string
+
+// Link issues
+const (
+	// LinkMissingObject is a link to deleted Object
+	LinkMissingObject LinkIssue = "missing_object"
+	// LinkEndless is a link which never expires
+	LinkEndless LinkIssue = "endless"
+	// LinkExpired is a link past its expiration time
+	LinkExpired LinkIssue = "expired"
+	// LinkInsecurePrivate is a link without secure hash to private Object
+	LinkInsecurePrivate LinkIssue = "insecure_private"
+	// LinkPermissiveGeo is a link with too broad Geo
+	LinkPermissiveGeo LinkIssue = "permissive_geo"
+)
+
+// AuditOptions configures AuditLinks
+type AuditOptions struct {
+	// PermissiveGeo reports whether Geo is too broad.
+	// By default empty Geo and Geo granting the whole world are permissive.
+	PermissiveGeo func(Geo) bool
+	// GC deletes links having any of GCIssues
+	GC bool
+	// GCIssues are deleted in GC mode, LinkMissingObject and LinkExpired by default.
+	// Endless and permissive links are usually intended, so they are deleted only when listed.
+	GCIssues []LinkIssue
+}
+
+// LinkFinding is a Link with issues
+type LinkFinding struct {
+	Link   Link
+	Issues []LinkIssue
+	// Deleted is set when the link was deleted in GC mode
+	Deleted bool
+}
+
+// AuditReport is result of AuditLinks
+type AuditReport struct {
+	// Checked is number of audited links
+	Checked  int
+	Findings []LinkFinding
+	// Counts is number of links by issue
+	Counts map[LinkIssue]int
+	// Deleted are IDs of links deleted in GC mode
+	Deleted []string
+	// Errors of object lookups and deletions, audit continues on them
+	Errors []error
+}
+
+// AuditLinks lists all temporary links, cross-checks them with objects
+// and reports links with issues. In GC mode offending links are deleted.
+func AuditLinks(ctx context.Context, temp TempService, objects ObjectsService, opts *AuditOptions) (*AuditReport, error) {
+	if opts == nil {
+		opts = new(AuditOptions)
+	}
+
+	permissive := opts.PermissiveGeo
+	if permissive == nil {
+		permissive = permissiveGeo
+	}
+
+	gcIssues := opts.GCIssues
+	if len(gcIssues) == 0 {
+		gcIssues = defaultGCIssues
+	}
+
+	links, _, err := temp.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AuditReport{
+		Checked: len(links),
+		Counts:  make(map[LinkIssue]int),
+	}
+
+	now := time.Now()
+	cache := make(map[string]*Object)
+
+	for _, link := range links {
+		var issues []LinkIssue
+
+		object, err := auditObject(ctx, objects, cache, link.ObjectID)
+		switch {
+		case err != nil:
+			report.Errors = append(report.Errors, fmt.Errorf("filespot: link %v object %v: %v", link.ID, link.ObjectID, err))
+		case object == nil:
+			issues = append(issues, LinkMissingObject)
+		case object.Private && !link.Secure:
+			issues = append(issues, LinkInsecurePrivate)
+		}
+
+		if link.Exp == 0 {
+			issues = append(issues, LinkEndless)
+		} else if linkExpires(&link, now) {
+			issues = append(issues, LinkExpired)
+		}
+
+		if permissive(link.Geo) {
+			issues = append(issues, LinkPermissiveGeo)
+		}
+
+		if len(issues) == 0 {
+			continue
+		}
+
+		finding := LinkFinding{Link: link, Issues: issues}
+		for _, issue := range issues {
+			report.Counts[issue]++
+		}
+
+		if opts.GC && hasIssue(issues, gcIssues) {
+			_, err := temp.Delete(ctx, link.ID)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("filespot: delete link %v: %v", link.ID, err))
+			} else {
+				finding.Deleted = true
+				report.Deleted = append(report.Deleted, link.ID)
+			}
+		}
+
+		report.Findings = append(report.Findings, finding)
+	}
+
+	return report, nil
+}
+
+// String returns a human readable report
+func (r *AuditReport) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "checked %d links, %d with issues, %d deleted\n", r.Checked, len(r.Findings), len(r.Deleted))
+	for _, f := range r.Findings {
+		issues := make([]string, len(f.Issues))
+		for i, issue := range f.Issues {
+			issues[i] = string(issue)
+		}
+
+		deleted := ""
+		if f.Deleted {
+			deleted = " (deleted)"
+		}
+
+		fmt.Fprintf(&sb, "%v object %v: %v%v\n", f.Link.ID, f.Link.ObjectID, strings.Join(issues, ", "), deleted)
+	}
+
+	for _, err := range r.Errors {
+		fmt.Fprintf(&sb, "error: %v\n", err)
+	}
+
+	return sb.String()
+}
+
+// auditObject returns Object of id, nil when it doesn't exist
+func auditObject(ctx context.Context, objects ObjectsService, cache map[string]*Object, id string) (*Object, error) {
+	if object, ok := cache[id]; ok {
+		return object, nil
+	}
+
+	object, _, err := objects.Get(ctx, id)
+	if err != nil {
+		if !notFound(err) {
+			return nil, err
+		}
+		object = nil
+	}
+
+	cache[id] = object
+	return object, nil
+}
+
+// permissiveGeo reports whether geo doesn't restrict access
+func permissiveGeo(geo Geo) bool {
+	return len(geo) == 0 || geo.grantsWorld()
+}
+
+// hasIssue reports whether issues contain any of wanted
+func hasIssue(issues, wanted []LinkIssue) bool {
+	for _, issue := range issues {
+		for _, w := range wanted {
+			if issue == w {
+				return true
+			}
+		}
+	}
+
+	return false
+}
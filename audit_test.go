@@ -0,0 +1,115 @@
+package filespot
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func handleAudit(t *testing.T, objectCalls *int, deleted *[]string) {
+	exp := time.Now().Add(time.Hour).Unix()
+	mux.HandleFunc("/1/temp", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{
+            "count": 4,
+            "links": [
+                {"id": "link1", "object_id": "56787f0c044dfe226b000001", "secure": true, "exp": %[1]v, "geo": {"EU": {"RU": true}}},
+                {"id": "link2", "object_id": "56787f0c044dfe226b000002", "secure": true, "exp": %[1]v, "geo": {"EU": {"RU": true}}},
+                {"id": "link3", "object_id": "56787f0c044dfe226b000001", "secure": false, "exp": 0, "geo": null},
+                {"id": "link4", "object_id": "56787f0c044dfe226b000003", "secure": false, "exp": 1492008763, "geo": {"EU": {"RU": true}}}
+            ]
+        }`, exp)
+	})
+
+	mux.HandleFunc("/1/objects/", func(w http.ResponseWriter, r *http.Request) {
+		*objectCalls++
+		switch r.URL.Path {
+		case "/1/objects/56787f0c044dfe226b000001":
+			fmt.Fprintf(w, `{"object": {"id": "56787f0c044dfe226b000001", "private": false}}`)
+		case "/1/objects/56787f0c044dfe226b000003":
+			fmt.Fprintf(w, `{"object": {"id": "56787f0c044dfe226b000003", "private": true}}`)
+		default:
+			http.Error(w, `{"code": 404, "status": "fail", "msg_user": "Object not found"}`, http.StatusNotFound)
+		}
+	})
+
+	mux.HandleFunc("/1/temp/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Temp request method = %v, expected %v", r.Method, http.MethodDelete)
+		}
+		*deleted = append(*deleted, strings.TrimPrefix(r.URL.Path, "/1/temp/"))
+		fmt.Fprintf(w, `{"code": 200, "status": "success"}`)
+	})
+}
+
+func TestAuditLinks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	objectCalls := 0
+	var deleted []string
+	handleAudit(t, &objectCalls, &deleted)
+
+	opts := &AuditOptions{
+		GC:       true,
+		GCIssues: []LinkIssue{LinkMissingObject, LinkEndless},
+	}
+	report, err := AuditLinks(ctx, client.Temp, client.Objects, opts)
+	if err != nil {
+		t.Fatalf("AuditLinks returned error: %v", err)
+	}
+
+	if objectCalls != 3 {
+		t.Errorf("AuditLinks object requests = %v, expected %v", objectCalls, 3)
+	}
+
+	expectedIssues := map[string][]LinkIssue{
+		"link2": {LinkMissingObject},
+		"link3": {LinkEndless, LinkPermissiveGeo},
+		"link4": {LinkInsecurePrivate, LinkExpired},
+	}
+
+	issues := make(map[string][]LinkIssue)
+	for _, f := range report.Findings {
+		issues[f.Link.ID] = f.Issues
+	}
+
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("AuditLinks issues = %v, expected %v", issues, expectedIssues)
+	}
+
+	expectedDeleted := []string{"link2", "link3"}
+	if !reflect.DeepEqual(report.Deleted, expectedDeleted) || !reflect.DeepEqual(deleted, expectedDeleted) {
+		t.Errorf("AuditLinks deleted = %v, expected %v", report.Deleted, expectedDeleted)
+	}
+
+	if report.Checked != 4 || report.Counts[LinkEndless] != 1 || len(report.Errors) != 0 {
+		t.Errorf("AuditLinks report = %v", report)
+	}
+}
+
+func TestAuditLinksDefaultGC(t *testing.T) {
+	setup()
+	defer teardown()
+
+	objectCalls := 0
+	var deleted []string
+	handleAudit(t, &objectCalls, &deleted)
+
+	report, err := AuditLinks(ctx, client.Temp, client.Objects, &AuditOptions{GC: true})
+	if err != nil {
+		t.Fatalf("AuditLinks returned error: %v", err)
+	}
+
+	// endless link3 with empty Geo is reported but kept
+	expectedDeleted := []string{"link2", "link4"}
+	if !reflect.DeepEqual(report.Deleted, expectedDeleted) || !reflect.DeepEqual(deleted, expectedDeleted) {
+		t.Errorf("AuditLinks deleted = %v, expected %v", report.Deleted, expectedDeleted)
+	}
+
+	if len(report.Findings) != 3 {
+		t.Errorf("AuditLinks findings = %v, expected 3", report.Findings)
+	}
+}
@@ -0,0 +1,232 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// playersPageSize is number of players requested per page by findPlayer
+const playersPageSize = 100
+
+// ErrNoRenditions is returned by EnsurePlayer when there are no video renditions
+var ErrNoRenditions = errors.New("filespot: no video renditions")
+
+// qualityHeights are standard heights of quality labels
+var qualityHeights = []uint32{240, 360, 480, 720, 1080, 1440, 2160}
+
+// PlayerOptions configures player created by EnsurePlayer
+type PlayerOptions struct {
+	// Name of the player, name of the source Object by default
+	Name         string
+	Folder       string
+	Description  string
+	VastAdTagURL string
+	Tags         []string
+	Geo          Geo
+}
+
+// QualityLabel returns quality label of video height, e.g. "720p".
+// Height slightly above or below a standard one, like 1072 or 1088, gets its label.
+func QualityLabel(height uint32) string {
+	label := uint32(0)
+	for _, h := range qualityHeights {
+		if height+height/20 >= h {
+			label = h
+		}
+	}
+
+	if label == 0 {
+		label = height
+	}
+
+	return strconv.FormatUint(uint64(label), 10) + "p"
+}
+
+// RenditionVideos maps quality labels to IDs of rendition objects.
+// Renditions without video stream are skipped, the highest bitrate wins for a label.
+func RenditionVideos(renditions []Object) map[string]string {
+	videos := make(map[string]string)
+	bitrates := make(map[string]uint32)
+
+	for i := range renditions {
+		rendition := &renditions[i]
+		stream := objectVideoStream(rendition)
+		if stream == nil || stream.Height == 0 {
+			continue
+		}
+
+		label := QualityLabel(stream.Height)
+		if _, ok := videos[label]; ok && bitrates[label] >= stream.BitRate {
+			continue
+		}
+
+		videos[label] = rendition.ID
+		bitrates[label] = stream.BitRate
+	}
+
+	return videos
+}
+
+// EnsurePlayer creates a player of source Object playing its transcoded renditions
+// or updates the existing player of the same name and folder when its videos or options differ.
+// It returns the player and whether it was created or updated.
+func EnsurePlayer(ctx context.Context, players PlayersService, source *Object, renditions []Object, opts *PlayerOptions) (*Player, bool, error) {
+	if opts == nil {
+		opts = new(PlayerOptions)
+	}
+
+	videos := RenditionVideos(renditions)
+	if len(videos) == 0 {
+		return nil, false, ErrNoRenditions
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = strings.TrimSuffix(source.Name, path.Ext(source.Name))
+	}
+
+	existing, err := findPlayer(ctx, players, name, opts.Folder)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		playerCreateRequest := &PlayerCreateRequest{
+			Name:         name,
+			Folder:       opts.Folder,
+			Videos:       videos,
+			ScreenShotID: previewID(source.Previews),
+			VastAdTagURL: opts.VastAdTagURL,
+			Description:  opts.Description,
+			Tags:         opts.Tags,
+			Geo:          opts.Geo,
+		}
+
+		player, _, err := players.Create(ctx, playerCreateRequest)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return player, true, nil
+	}
+
+	if sameVideos(existing.Videos, videos, renditions) && sameOptions(existing, opts) {
+		return existing, false, nil
+	}
+
+	playerUpdateRequest := &PlayerUpdateRequest{
+		Name:         name,
+		Folder:       opts.Folder,
+		Videos:       videos,
+		ScreenShotID: previewID(source.Previews),
+		VastAdTagURL: opts.VastAdTagURL,
+		Description:  opts.Description,
+		Tags:         opts.Tags,
+		Geo:          opts.Geo,
+	}
+
+	_, err = players.Update(ctx, existing.ID, playerUpdateRequest)
+	if err != nil {
+		return nil, false, err
+	}
+
+	player, _, err := players.Get(ctx, existing.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return player, true, nil
+}
+
+// findPlayer returns player of name in folder reading every page of players, nil when there is none
+func findPlayer(ctx context.Context, players PlayersService, name, folder string) (*Player, error) {
+	params := &PlayersListParams{
+		Folder: folder,
+		Name:   name,
+		Limit:  playersPageSize,
+	}
+
+	playerPath := path.Join("/", folder, name)
+	for {
+		list, _, err := players.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list {
+			if list[i].IsDir {
+				continue
+			}
+
+			if list[i].Path == playerPath || (list[i].Path == "" && list[i].Name == name) {
+				return &list[i], nil
+			}
+		}
+
+		if len(list) < params.Limit {
+			return nil, nil
+		}
+		params.Start += len(list)
+	}
+}
+
+// sameOptions reports whether player has description, ad tag, tags and geo of opts
+func sameOptions(player *Player, opts *PlayerOptions) bool {
+	if player.Description != opts.Description || player.VastAdTagURL != opts.VastAdTagURL {
+		return false
+	}
+
+	current := append([]string(nil), player.Tags...)
+	wanted := append([]string(nil), opts.Tags...)
+	sort.Strings(current)
+	sort.Strings(wanted)
+	if strings.Join(current, ",") != strings.Join(wanted, ",") {
+		return false
+	}
+
+	return player.Geo.Equal(opts.Geo)
+}
+
+// sameVideos reports whether player videos, which API returns as URLs, are the wanted renditions
+func sameVideos(current videos, wanted map[string]string, renditions []Object) bool {
+	if len(current) != len(wanted) {
+		return false
+	}
+
+	urls := make(map[string]string, len(renditions))
+	for _, rendition := range renditions {
+		urls[rendition.ID] = stripScheme(rendition.CDNURL)
+	}
+
+	for label, id := range wanted {
+		video, ok := current[label]
+		if !ok {
+			return false
+		}
+
+		if video != id && (urls[id] == "" || stripScheme(video) != urls[id]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// previewID returns ID of preview for player screenshot.
+// Middle preview is preferred as the first frames are often black.
+func previewID(previews []string) string {
+	n := len(previews)
+	for i := 0; i < n; i++ {
+		preview := previews[(n/2+i)%n]
+		id := strings.TrimSuffix(path.Base(preview), path.Ext(preview))
+		if idSegment.MatchString(id) {
+			return id
+		}
+	}
+
+	return ""
+}
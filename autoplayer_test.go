@@ -0,0 +1,238 @@
+package filespot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fakePlayers is in-memory PlayersService which returns videos as URLs like API does
+type fakePlayers struct {
+	PlayersService
+	players map[string]*Player
+	urls    map[string]string
+	creates int
+	updates int
+	lists   int
+}
+
+// List returns page of players ordered by ID
+func (f *fakePlayers) List(ctx context.Context, params *PlayersListParams) ([]Player, *http.Response, error) {
+	f.lists++
+
+	var list []Player
+	for _, p := range f.players {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	start := params.Start
+	if start > len(list) {
+		start = len(list)
+	}
+	end := len(list)
+	if params.Limit > 0 && start+params.Limit < end {
+		end = start + params.Limit
+	}
+
+	return list[start:end], nil, nil
+}
+
+func (f *fakePlayers) Get(ctx context.Context, id string) (*Player, *http.Response, error) {
+	return f.players[id], nil, nil
+}
+
+func (f *fakePlayers) Create(ctx context.Context, r *PlayerCreateRequest) (*Player, *http.Response, error) {
+	f.creates++
+	id := "567d3643534b4474087c221e"
+	f.players[id] = &Player{
+		ID:           id,
+		Name:         r.Name,
+		Path:         path.Join("/", r.Folder, r.Name),
+		Videos:       f.resolve(r.Videos),
+		Description:  r.Description,
+		VastAdTagURL: r.VastAdTagURL,
+		Tags:         r.Tags,
+		Geo:          r.Geo,
+	}
+	return f.players[id], nil, nil
+}
+
+func (f *fakePlayers) Update(ctx context.Context, id string, r *PlayerUpdateRequest) (*http.Response, error) {
+	f.updates++
+	f.players[id].Videos = f.resolve(r.Videos)
+	f.players[id].Description = r.Description
+	f.players[id].VastAdTagURL = r.VastAdTagURL
+	f.players[id].Tags = r.Tags
+	f.players[id].Geo = r.Geo
+	return nil, nil
+}
+
+func (f *fakePlayers) resolve(v videos) videos {
+	resolved := make(videos)
+	for label, id := range v {
+		resolved[label] = f.urls[id]
+	}
+	return resolved
+}
+
+func autoPlayerRendition(id string, height, bitrate uint32) Object {
+	return Object{
+		ID:     id,
+		CDNURL: "cdn.platformcraft.ru/alex/" + id + ".mp4",
+		Advanced: &ObjectAdvanced{
+			VideoStreams: []ObjectVideoStream{{Height: height, BitRate: bitrate}},
+		},
+	}
+}
+
+func TestQualityLabel(t *testing.T) {
+	tests := map[uint32]string{
+		144:  "144p",
+		240:  "240p",
+		360:  "360p",
+		404:  "360p",
+		480:  "480p",
+		720:  "720p",
+		1072: "1080p",
+		1080: "1080p",
+		2160: "2160p",
+	}
+
+	for height, expected := range tests {
+		if label := QualityLabel(height); label != expected {
+			t.Errorf("QualityLabel(%v) = %v, expected %v", height, label, expected)
+		}
+	}
+}
+
+func TestRenditionVideos(t *testing.T) {
+	renditions := []Object{
+		autoPlayerRendition("56787f0c044dfe226b000001", 360, 800000),
+		autoPlayerRendition("56787f0c044dfe226b000002", 720, 2500000),
+		autoPlayerRendition("56787f0c044dfe226b000003", 720, 4000000),
+		{ID: "56787f0c044dfe226b000004"},
+	}
+
+	expected := map[string]string{
+		"360p": "56787f0c044dfe226b000001",
+		"720p": "56787f0c044dfe226b000003",
+	}
+
+	if videos := RenditionVideos(renditions); !reflect.DeepEqual(videos, expected) {
+		t.Errorf("RenditionVideos = %v, expected %v", videos, expected)
+	}
+}
+
+func TestEnsurePlayer(t *testing.T) {
+	source := &Object{
+		ID:   "56787f0c044dfe226b000000",
+		Name: "example.mp4",
+		Previews: []string{
+			"cdn.platformcraft.ru/alex/56787f0c044dfe226b0000a1.jpg",
+			"cdn.platformcraft.ru/alex/56787f0c044dfe226b0000a2.jpg",
+			"cdn.platformcraft.ru/alex/56787f0c044dfe226b0000a3.jpg",
+		},
+	}
+	renditions := []Object{
+		autoPlayerRendition("56787f0c044dfe226b000001", 360, 800000),
+		autoPlayerRendition("56787f0c044dfe226b000002", 720, 2500000),
+	}
+
+	players := &fakePlayers{players: make(map[string]*Player), urls: make(map[string]string)}
+	for _, r := range append(renditions, autoPlayerRendition("56787f0c044dfe226b000003", 1080, 5000000)) {
+		players.urls[r.ID] = r.CDNURL
+	}
+
+	player, changed, err := EnsurePlayer(ctx, players, source, renditions, nil)
+	if err != nil {
+		t.Fatalf("EnsurePlayer returned error: %v", err)
+	}
+	if !changed || players.creates != 1 || player.Name != "example" {
+		t.Errorf("EnsurePlayer = %v, %v, expected created player example", player.Name, changed)
+	}
+
+	_, changed, err = EnsurePlayer(ctx, players, source, renditions, nil)
+	if err != nil {
+		t.Fatalf("EnsurePlayer returned error: %v", err)
+	}
+	if changed || players.creates != 1 || players.updates != 0 {
+		t.Errorf("EnsurePlayer changed = %v, expected unchanged player", changed)
+	}
+
+	renditions = append(renditions, autoPlayerRendition("56787f0c044dfe226b000003", 1080, 5000000))
+	player, changed, err = EnsurePlayer(ctx, players, source, renditions, nil)
+	if err != nil {
+		t.Fatalf("EnsurePlayer returned error: %v", err)
+	}
+	if !changed || players.updates != 1 || len(player.Videos) != 3 {
+		t.Errorf("EnsurePlayer = %v, %v, expected updated player with 3 videos", player.Videos, changed)
+	}
+
+	_, _, err = EnsurePlayer(ctx, players, source, nil, nil)
+	if err != ErrNoRenditions {
+		t.Errorf("EnsurePlayer returned error: %v, expected %v", err, ErrNoRenditions)
+	}
+}
+
+func TestEnsurePlayerOptions(t *testing.T) {
+	source := &Object{ID: "56787f0c044dfe226b000000", Name: "example.mp4"}
+	renditions := []Object{autoPlayerRendition("56787f0c044dfe226b000001", 360, 800000)}
+
+	players := &fakePlayers{players: make(map[string]*Player), urls: make(map[string]string)}
+	players.urls[renditions[0].ID] = renditions[0].CDNURL
+
+	// other players of the folder push the wanted one to the next page
+	for i := 0; i < playersPageSize; i++ {
+		id := fmt.Sprintf("467d3643534b4474087c%04d", i)
+		players.players[id] = &Player{ID: id, Name: "example", Path: "/other/example"}
+	}
+
+	opts := &PlayerOptions{Tags: []string{"sport", "live"}, Geo: NewGeo().AllowCountry("RU")}
+	_, _, err := EnsurePlayer(ctx, players, source, renditions, opts)
+	if err != nil {
+		t.Fatalf("EnsurePlayer returned error: %v", err)
+	}
+
+	steps := []struct {
+		change  func()
+		changed bool
+	}{
+		{func() { opts.Tags = []string{"live", "sport"} }, false},
+		{func() { opts.Geo = NewGeo().AllowContinent("EU") }, true},
+		{func() { opts.Tags = []string{"live"} }, true},
+		{func() { opts.VastAdTagURL = "http://example.com/example-vast.xml" }, true},
+		{func() {}, false},
+	}
+
+	for i, step := range steps {
+		step.change()
+		players.lists = 0
+
+		_, changed, err := EnsurePlayer(ctx, players, source, renditions, opts)
+		if err != nil {
+			t.Fatalf("step %d: EnsurePlayer returned error: %v", i, err)
+		}
+
+		if changed != step.changed || players.creates != 1 || players.lists != 2 {
+			t.Errorf("step %d: EnsurePlayer changed = %v with %v creates and %v lists, expected %v of one player on the second page", i, changed, players.creates, players.lists, step.changed)
+		}
+	}
+}
+
+func TestPreviewID(t *testing.T) {
+	previews := []string{
+		"cdn.platformcraft.ru/alex/56787f0c044dfe226b0000a1.jpg",
+		"cdn.platformcraft.ru/alex/poster.jpg",
+		"cdn.platformcraft.ru/alex/56787f0c044dfe226b0000a3.jpg",
+	}
+
+	expected := "56787f0c044dfe226b0000a3"
+	if id := previewID(previews); id != expected {
+		t.Errorf("previewID = %v, expected %v", id, expected)
+	}
+}
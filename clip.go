@@ -0,0 +1,162 @@
+package filespot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrClipRange is returned by Clipper when clip isn't within source duration
+	ErrClipRange = errors.New("filespot: clip range is out of source duration")
+	// ErrUnknownDuration is returned by Clipper when source has no duration
+	ErrUnknownDuration = errors.New("filespot: source duration is unknown")
+)
+
+// ClipRange is a part of source video
+type ClipRange struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// Clipper extracts clips of source Object via TranscoderService.Create.
+// Transcoder takes whole seconds, so From is rounded down and To is rounded up
+// within the whole seconds of source duration.
+type Clipper struct {
+	Client *Client
+	// Path is a folder of clips, "/clips/<object ID>" by default.
+	// Every clip is put into its own subfolder "<start>-<end>/<run>-<i>", where start and end
+	// are in seconds, run is unique for every call of Clips and i is index of the range,
+	// so neither reruns nor ranges rounded to the same seconds mix objects.
+	Path string
+	// Concurrency limits number of clips transcoded at once, 4 by default
+	Concurrency int
+	// PollInterval of transcoder tasks, five seconds by default
+	PollInterval time.Duration
+}
+
+// NewClipper returns Clipper using client
+func NewClipper(client *Client) *Clipper {
+	return &Clipper{
+		Client: client,
+	}
+}
+
+// Clip extracts part of object from..to with presets and returns the new objects
+func (c *Clipper) Clip(ctx context.Context, objectID string, from, to time.Duration, presets []string) ([]Object, error) {
+	clips, err := c.Clips(ctx, objectID, []ClipRange{{From: from, To: to}}, presets)
+	if err != nil {
+		return nil, err
+	}
+
+	return clips[0], nil
+}
+
+// Clips extracts many parts of object with presets concurrently.
+// All ranges are validated before the first task is created.
+// It returns the new objects of every range in order of ranges and stops on the first error.
+func (c *Clipper) Clips(ctx context.Context, objectID string, ranges []ClipRange, presets []string) ([][]Object, error) {
+	source, _, err := c.Client.Objects.Get(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := runName(time.Now())
+
+	requests := make([]*TranscoderCreateRequest, len(ranges))
+	for i, r := range ranges {
+		requests[i], err = c.request(source, r, presets, fmt.Sprintf("%v-%d", run, i))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([][]Object, len(requests))
+
+	err = forEach(ctx, len(requests), c.Concurrency, func(ctx context.Context, i int) error {
+		objects, err := transcodeObjects(ctx, c.Client, source.ID, requests[i], c.PollInterval)
+		if err != nil {
+			return fmt.Errorf("filespot: clip %v-%v: %v", ranges[i].From, ranges[i].To, err)
+		}
+
+		result[i] = objects
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// request returns transcoder request of clip into folder named run after checking it's within source
+func (c *Clipper) request(source *Object, r ClipRange, presets []string, run string) (*TranscoderCreateRequest, error) {
+	if source.Advanced == nil || source.Advanced.Format == nil || source.Advanced.Format.Duration <= 0 {
+		return nil, ErrUnknownDuration
+	}
+
+	duration := time.Duration(float64(source.Advanced.Format.Duration) * float64(time.Second))
+	if r.From < 0 || r.To <= r.From || r.To > duration {
+		return nil, ErrClipRange
+	}
+
+	start := int(r.From / time.Second)
+	end := int(math.Ceil(r.To.Seconds()))
+	if last := int(duration / time.Second); end > last {
+		end = last
+	}
+	if end <= start {
+		return nil, ErrClipRange
+	}
+
+	path := c.Path
+	if path == "" {
+		path = "/clips/" + source.ID
+	}
+
+	return &TranscoderCreateRequest{
+		Presets:  presets,
+		Path:     fmt.Sprintf("%v/%d-%d/%v", path, start, end, run),
+		Start:    start,
+		Duration: end - start,
+	}, nil
+}
+
+// runName returns unique folder name of run started at t
+func runName(t time.Time) string {
+	b := make([]byte, 3)
+	rand.Read(b)
+
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// transcodeObjects creates transcoder task, waits for it and returns objects of its folder
+func transcodeObjects(ctx context.Context, client *Client, id string, request *TranscoderCreateRequest, interval time.Duration) ([]Object, error) {
+	transcoder, _, err := client.Transcoder.Create(ctx, id, request)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = WaitTask(ctx, client.TranscoderTasks, transcoder.TaskID, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, _, err := client.Objects.List(ctx, &ObjectsListParams{Folder: request.Path})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]Object, 0, len(objects))
+	for _, object := range objects {
+		if !object.IsDir {
+			files = append(files, object)
+		}
+	}
+
+	return files, nil
+}
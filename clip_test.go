@@ -0,0 +1,201 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clipFolder matches run folder of clip
+var clipFolder = regexp.MustCompile(`/\d+-\d+/\d{8}T\d{6}Z-[0-9a-f]{6}-\d+$`)
+
+func handleClips(t *testing.T, requests *[]TranscoderCreateRequest) {
+	var mu sync.Mutex
+
+	mux.HandleFunc("/1/objects/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "object": {"id": "5bd37808534b441c4acf7415", "name": "match.mp4", "advanced": {"format": {"duration": 90.5}}}}`)
+	})
+
+	mux.HandleFunc("/1/transcoder/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TranscoderCreateRequest)
+		json.NewDecoder(r.Body).Decode(v)
+
+		mu.Lock()
+		*requests = append(*requests, *v)
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0001"}`)
+	})
+
+	mux.HandleFunc("/1/transcoder_tasks/5bd37808534b441c4acf0001", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task": {"id": "5bd37808534b441c4acf0001", "status": "Completed"}}`)
+	})
+
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		folder := r.URL.Query().Get("folder")
+		fmt.Fprintf(w, `{"code": 200, "status": "success", "objects": [
+            {"id": "5bd37808534b441c4acf0000", "name": "sub", "is_dir": true},
+            {"id": "5bd37808534b441c4acf0720", "name": "clip.mp4", "path": %q}
+        ]}`, folder+"/clip.mp4")
+	})
+}
+
+func TestClipper_Clip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests []TranscoderCreateRequest
+	handleClips(t, &requests)
+
+	clipper := NewClipper(client)
+	clipper.PollInterval = time.Millisecond
+
+	objects, err := clipper.Clip(ctx, "5bd37808534b441c4acf7415", 1500*time.Millisecond, 10200*time.Millisecond, []string{"566b0fbf044dfe64f2000002"})
+	if err != nil {
+		t.Fatalf("Clipper.Clip returned error: %v", err)
+	}
+
+	if len(requests) != 1 || !clipFolder.MatchString(requests[0].Path) {
+		t.Fatalf("Transcoder.Create requests = %+v, expected one into clip folder", requests)
+	}
+
+	folder := requests[0].Path
+	if !strings.HasPrefix(folder, "/clips/5bd37808534b441c4acf7415/1-11/") {
+		t.Errorf("Transcoder.Create path = %v, expected run folder of /clips/5bd37808534b441c4acf7415/1-11", folder)
+	}
+
+	expected := []TranscoderCreateRequest{{
+		Presets:  []string{"566b0fbf044dfe64f2000002"},
+		Path:     folder,
+		Start:    1,
+		Duration: 10,
+	}}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Transcoder.Create requests = %+v, expected %+v", requests, expected)
+	}
+
+	if len(objects) != 1 || objects[0].Path != folder+"/clip.mp4" {
+		t.Errorf("Clipper.Clip = %+v, expected clip.mp4 of the clip folder", objects)
+	}
+
+	// rerun doesn't share folder with the previous clip
+	_, err = clipper.Clip(ctx, "5bd37808534b441c4acf7415", 1500*time.Millisecond, 10200*time.Millisecond, []string{"566b0fbf044dfe64f2000002"})
+	if err != nil || len(requests) != 2 || requests[1].Path == folder {
+		t.Errorf("Clipper.Clip rerun requests = %+v, %v, expected another folder", requests, err)
+	}
+}
+
+func TestClipper_Clips(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests []TranscoderCreateRequest
+	handleClips(t, &requests)
+
+	clipper := NewClipper(client)
+	clipper.Path = "/highlights"
+	clipper.Concurrency = 2
+	clipper.PollInterval = time.Millisecond
+
+	ranges := []ClipRange{
+		{From: 0, To: 5 * time.Second},
+		{From: 30 * time.Second, To: 45 * time.Second},
+		{From: 80 * time.Second, To: 90500 * time.Millisecond},
+	}
+
+	clips, err := clipper.Clips(ctx, "5bd37808534b441c4acf7415", ranges, []string{"566b0fbf044dfe64f2000002"})
+	if err != nil {
+		t.Fatalf("Clipper.Clips returned error: %v", err)
+	}
+
+	// the last clip is cut to the whole seconds of source
+	expected := []string{"/highlights/0-5/", "/highlights/30-45/", "/highlights/80-90/"}
+	for i, objects := range clips {
+		if len(objects) != 1 || !strings.HasPrefix(objects[0].Path, expected[i]) || !clipFolder.MatchString(path.Dir(objects[0].Path)) {
+			t.Errorf("Clipper.Clips[%d] = %+v, expected clip of %v", i, objects, expected[i])
+		}
+	}
+
+	if len(requests) != len(ranges) {
+		t.Errorf("Transcoder.Create called %d times, expected %d", len(requests), len(ranges))
+	}
+}
+
+func TestClipper_ClipsRounded(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests []TranscoderCreateRequest
+	handleClips(t, &requests)
+
+	clipper := NewClipper(client)
+	clipper.PollInterval = time.Millisecond
+
+	// both ranges are 0-10 in whole seconds
+	ranges := []ClipRange{
+		{From: 0, To: 9500 * time.Millisecond},
+		{From: 0, To: 10 * time.Second},
+	}
+
+	clips, err := clipper.Clips(ctx, "5bd37808534b441c4acf7415", ranges, nil)
+	if err != nil {
+		t.Fatalf("Clipper.Clips returned error: %v", err)
+	}
+
+	if len(requests) != 2 || requests[0].Path == requests[1].Path {
+		t.Fatalf("Transcoder.Create requests = %+v, expected two folders", requests)
+	}
+
+	if clips[0][0].Path == clips[1][0].Path {
+		t.Errorf("Clipper.Clips = %+v, expected objects of separate folders", clips)
+	}
+}
+
+func TestClipper_ClipsRange(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests []TranscoderCreateRequest
+	handleClips(t, &requests)
+
+	clipper := NewClipper(client)
+
+	cases := []ClipRange{
+		{From: -time.Second, To: 5 * time.Second},
+		{From: 5 * time.Second, To: 5 * time.Second},
+		{From: 80 * time.Second, To: 91 * time.Second},
+		{From: 90200 * time.Millisecond, To: 90500 * time.Millisecond},
+	}
+
+	for _, r := range cases {
+		_, err := clipper.Clips(ctx, "5bd37808534b441c4acf7415", []ClipRange{{From: 0, To: time.Second}, r}, nil)
+		if err != ErrClipRange {
+			t.Errorf("Clipper.Clips(%v) error = %v, expected %v", r, err, ErrClipRange)
+		}
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("Transcoder.Create called %d times, expected no calls for invalid ranges", len(requests))
+	}
+}
+
+func TestClipper_ClipUnknownDuration(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/objects/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "object": {"id": "5bd37808534b441c4acf7415"}}`)
+	})
+
+	_, err := NewClipper(client).Clip(ctx, "5bd37808534b441c4acf7415", 0, time.Second, nil)
+	if err != ErrUnknownDuration {
+		t.Errorf("Clipper.Clip error = %v, expected %v", err, ErrUnknownDuration)
+	}
+}
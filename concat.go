@@ -0,0 +1,308 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fpsTolerance is a difference of frame rates considered equal
+const fpsTolerance = 0.01
+
+var (
+	// ErrIncompatibleInputs is returned by ConcatBuilder.Build when inputs differ and no normalize preset is set
+	ErrIncompatibleInputs = errors.New("filespot: concat inputs are incompatible")
+	// ErrConcatResult is returned by ConcatBuilder.Build when the concatenated object isn't found
+	ErrConcatResult = errors.New("filespot: concatenated object not found")
+)
+
+// ConcatBuilder concatenates objects via TranscoderService.Concat checking
+// the inputs share video codec, resolution, frame rate and audio layout first.
+// Incompatible inputs are transcoded with NormalizePreset when it's set.
+type ConcatBuilder struct {
+	Client *Client
+	Files  []string
+	// Path and Name of the result. The result is the object which appears in Path
+	// during Build and whose name starts with Name without extension, as API may add one.
+	Path string
+	Name string
+	// NormalizePreset transcodes every input before concat when inputs are incompatible.
+	// Inputs are normalized into "<Path>/.normalized/<run>/<i>" folders, the objects
+	// are deleted after concat unless KeepNormalized is set, the empty folders remain.
+	NormalizePreset string
+	KeepNormalized  bool
+	// PollInterval of transcoder tasks, five seconds by default
+	PollInterval time.Duration
+}
+
+// ConcatIssue is a property of input which differs from the first input
+type ConcatIssue struct {
+	ObjectID string
+	Property string
+	Expected string
+	Actual   string
+}
+
+// ConcatPlan is result of ConcatBuilder.Check
+type ConcatPlan struct {
+	Inputs []Object
+	Issues []ConcatIssue
+	// Duration is the expected duration of the result
+	Duration time.Duration
+}
+
+// concatProfile is compared properties of input
+type concatProfile struct {
+	videoCodec   string
+	resolution   string
+	fps          float64
+	audioCodec   string
+	audioLayout  string
+	sampleRate   uint32
+	audioPresent bool
+}
+
+// NewConcatBuilder returns ConcatBuilder putting result to path/name
+func NewConcatBuilder(client *Client, path, name string) *ConcatBuilder {
+	return &ConcatBuilder{
+		Client: client,
+		Path:   path,
+		Name:   name,
+	}
+}
+
+// String returns description of issue
+func (i ConcatIssue) String() string {
+	return fmt.Sprintf("%v: %v is %v, expected %v", i.ObjectID, i.Property, i.Actual, i.Expected)
+}
+
+// Add appends objects to inputs
+func (b *ConcatBuilder) Add(ids ...string) *ConcatBuilder {
+	b.Files = append(b.Files, ids...)
+	return b
+}
+
+// Check fetches inputs and compares them with the first one
+func (b *ConcatBuilder) Check(ctx context.Context) (*ConcatPlan, error) {
+	if len(b.Files) < 2 {
+		return nil, errors.New("filespot: concat needs at least two inputs")
+	}
+
+	plan := &ConcatPlan{
+		Inputs: make([]Object, len(b.Files)),
+	}
+
+	var reference *concatProfile
+	for i, id := range b.Files {
+		object, _, err := b.Client.Objects.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		plan.Inputs[i] = *object
+
+		if object.Advanced != nil && object.Advanced.Format != nil {
+			plan.Duration += time.Duration(float64(object.Advanced.Format.Duration) * float64(time.Second))
+		}
+
+		profile := profileOf(object)
+		if reference == nil {
+			reference = profile
+			continue
+		}
+
+		plan.Issues = append(plan.Issues, reference.compare(object.ID, profile)...)
+	}
+
+	return plan, nil
+}
+
+// Build checks inputs, normalizes them when needed, concatenates them and waits for the result
+func (b *ConcatBuilder) Build(ctx context.Context) (*Object, *ConcatPlan, error) {
+	plan, err := b.Check(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := b.Files
+	if len(plan.Issues) > 0 {
+		if b.NormalizePreset == "" {
+			return nil, plan, ErrIncompatibleInputs
+		}
+
+		files, err = b.normalize(ctx)
+		if err != nil {
+			return nil, plan, err
+		}
+
+		if !b.KeepNormalized {
+			defer b.remove(ctx, files)
+		}
+	}
+
+	existing, err := b.objects(ctx)
+	if err != nil {
+		return nil, plan, err
+	}
+
+	transcoderConcatRequest := &TranscoderConcatRequest{
+		Files: files,
+		Path:  b.Path,
+		Name:  b.Name,
+	}
+
+	transcoder, _, err := b.Client.Transcoder.Concat(ctx, transcoderConcatRequest)
+	if err != nil {
+		return nil, plan, err
+	}
+
+	_, err = WaitTask(ctx, b.Client.TranscoderTasks, transcoder.TaskID, b.PollInterval)
+	if err != nil {
+		return nil, plan, err
+	}
+
+	object, err := b.result(ctx, existing)
+	return object, plan, err
+}
+
+// objects returns files of Path by ID
+func (b *ConcatBuilder) objects(ctx context.Context) (map[string]*Object, error) {
+	objects, _, err := b.Client.Objects.List(ctx, &ObjectsListParams{Folder: b.Path})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*Object, len(objects))
+	for i := range objects {
+		if !objects[i].IsDir {
+			files[objects[i].ID] = &objects[i]
+		}
+	}
+
+	return files, nil
+}
+
+// result returns the new object of Path matching Name, an existing object
+// named Name is returned when nothing new appeared as the result may replace it
+func (b *ConcatBuilder) result(ctx context.Context, existing map[string]*Object) (*Object, error) {
+	objects, err := b.objects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(b.Name, path.Ext(b.Name))
+
+	var found *Object
+	for id, object := range objects {
+		if _, ok := existing[id]; ok || !strings.HasPrefix(object.Name, prefix) {
+			continue
+		}
+
+		// several results are ambiguous
+		if found != nil {
+			return nil, ErrConcatResult
+		}
+		found = object
+	}
+
+	if found != nil {
+		return found, nil
+	}
+
+	for _, object := range objects {
+		if b.Name != "" && object.Name == b.Name {
+			return object, nil
+		}
+	}
+
+	return nil, ErrConcatResult
+}
+
+// remove deletes normalized objects, errors are ignored as they don't affect the result
+func (b *ConcatBuilder) remove(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		b.Client.Objects.Delete(ctx, id)
+	}
+}
+
+// normalize transcodes every input with NormalizePreset and returns IDs of results,
+// results of inputs normalized before an error are deleted unless KeepNormalized is set
+func (b *ConcatBuilder) normalize(ctx context.Context) ([]string, error) {
+	run := runName(time.Now())
+
+	var files []string
+	for i, id := range b.Files {
+		transcoderCreateRequest := &TranscoderCreateRequest{
+			Presets: []string{b.NormalizePreset},
+			Path:    path.Join(b.Path, ".normalized", run, strconv.Itoa(i)),
+		}
+
+		objects, err := transcodeObjects(ctx, b.Client, id, transcoderCreateRequest, b.PollInterval)
+		if err == nil && len(objects) == 0 {
+			err = errors.New("no output")
+		}
+		if err != nil {
+			if !b.KeepNormalized {
+				b.remove(ctx, files)
+			}
+			return nil, fmt.Errorf("filespot: normalize %v: %v", id, err)
+		}
+
+		files = append(files, objects[0].ID)
+	}
+
+	return files, nil
+}
+
+// profileOf returns compared properties of object
+func profileOf(object *Object) *concatProfile {
+	profile := new(concatProfile)
+
+	if stream := objectVideoStream(object); stream != nil {
+		profile.videoCodec = stream.CodecName
+		profile.resolution = fmt.Sprintf("%dx%d", stream.Width, stream.Height)
+		profile.fps = float64(stream.FPS)
+	}
+
+	if object.Advanced != nil && len(object.Advanced.AudioStreams) > 0 {
+		audio := object.Advanced.AudioStreams[0]
+		profile.audioPresent = true
+		profile.audioCodec = audio.CodecLongName
+		profile.audioLayout = audio.ChannelLayout
+		if profile.audioLayout == "" {
+			profile.audioLayout = fmt.Sprintf("%d channels", audio.Channels)
+		}
+		profile.sampleRate = audio.SampleRate
+	}
+
+	return profile
+}
+
+// compare returns issues of profile p differing from the reference
+func (ref *concatProfile) compare(id string, p *concatProfile) []ConcatIssue {
+	var issues []ConcatIssue
+	add := func(property, expected, actual string) {
+		if expected != actual {
+			issues = append(issues, ConcatIssue{ObjectID: id, Property: property, Expected: expected, Actual: actual})
+		}
+	}
+
+	add("video codec", ref.videoCodec, p.videoCodec)
+	add("resolution", ref.resolution, p.resolution)
+	if math.Abs(ref.fps-p.fps) > fpsTolerance {
+		add("fps", strconv.FormatFloat(ref.fps, 'f', -1, 32), strconv.FormatFloat(p.fps, 'f', -1, 32))
+	}
+	add("audio", strconv.FormatBool(ref.audioPresent), strconv.FormatBool(p.audioPresent))
+
+	if ref.audioPresent && p.audioPresent {
+		add("audio codec", ref.audioCodec, p.audioCodec)
+		add("audio layout", ref.audioLayout, p.audioLayout)
+		add("sample rate", strconv.Itoa(int(ref.sampleRate)), strconv.Itoa(int(p.sampleRate)))
+	}
+
+	return issues
+}
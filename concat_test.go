@@ -0,0 +1,224 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	concatInput720 = `{"id": "%v", "name": "%v.mp4", "advanced": {
+        "format": {"duration": %v},
+        "video_streams": [{"codec_name": "h264", "width": 1280, "height": 720, "fps": 25}],
+        "audio_streams": [{"codec_long_name": "AAC (Advanced Audio Coding)", "channel_layout": "stereo", "sample_rate": 44100}]
+    }}`
+	concatInput1080 = `{"id": "%v", "name": "%v.mp4", "advanced": {
+        "format": {"duration": %v},
+        "video_streams": [{"codec_name": "hevc", "width": 1920, "height": 1080, "fps": 29.97}],
+        "audio_streams": [{"codec_long_name": "AAC (Advanced Audio Coding)", "channel_layout": "mono", "sample_rate": 44100}]
+    }}`
+)
+
+// normalizedRun matches run folder of normalized inputs
+var normalizedRun = regexp.MustCompile(`/\d{8}T\d{6}Z-[0-9a-f]{6}/`)
+
+// handleConcat serves inputs, folder of results has match.mp4 of the previous run,
+// concat adds an object named as requested with .mp4 extension
+func handleConcat(t *testing.T, inputs map[string]string, calls *[]string) {
+	var (
+		mu     sync.Mutex
+		result string
+	)
+	record := func(call string) {
+		mu.Lock()
+		*calls = append(*calls, normalizedRun.ReplaceAllString(call, "/<run>/"))
+		mu.Unlock()
+	}
+
+	mux.HandleFunc("/1/objects/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/1/objects/")
+		if r.Method == http.MethodDelete {
+			record("delete " + id)
+			fmt.Fprint(w, `{"code": 200, "status": "success"}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"code": 200, "status": "success", "object": %v}`, inputs[id])
+	})
+
+	mux.HandleFunc("/1/transcoder/", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TranscoderCreateRequest)
+		json.NewDecoder(r.Body).Decode(v)
+		record(fmt.Sprintf("transcode %v %v %v", strings.TrimPrefix(r.URL.Path, "/1/transcoder/"), v.Presets, v.Path))
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0001"}`)
+	})
+
+	mux.HandleFunc("/1/transcoder", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TranscoderConcatRequest)
+		json.NewDecoder(r.Body).Decode(v)
+		record(fmt.Sprintf("concat %v %v/%v", v.Files, v.Path, v.Name))
+
+		mu.Lock()
+		result = strings.TrimSuffix(v.Name, ".mp4") + ".mp4"
+		mu.Unlock()
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0002"}`)
+	})
+
+	mux.HandleFunc("/1/transcoder_tasks/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task": {"status": "Completed"}}`)
+	})
+
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		folder := r.URL.Query().Get("folder")
+		switch {
+		case strings.Contains(folder, "/.normalized/") && strings.HasSuffix(folder, "/0"):
+			fmt.Fprint(w, `{"code": 200, "status": "success", "objects": [{"id": "5bd37808534b441c4acfaa00"}]}`)
+		case strings.Contains(folder, "/.normalized/") && strings.HasSuffix(folder, "/1"):
+			fmt.Fprint(w, `{"code": 200, "status": "success", "objects": [{"id": "5bd37808534b441c4acfaa01"}]}`)
+		default:
+			mu.Lock()
+			name := result
+			mu.Unlock()
+
+			fmt.Fprint(w, `{"code": 200, "status": "success", "objects": [
+                {"id": "5bd37808534b441c4acf0000", "name": ".normalized", "is_dir": true},
+                {"id": "5bd37808534b441c4acfbbbb", "name": "match.mp4"}`)
+			if name != "" {
+				fmt.Fprintf(w, `, {"id": "5bd37808534b441c4acfcccc", "name": %q}`, name)
+			}
+			fmt.Fprint(w, `]}`)
+		}
+	})
+}
+
+func TestConcatBuilder_Build(t *testing.T) {
+	setup()
+	defer teardown()
+
+	inputs := map[string]string{
+		"5bd37808534b441c4acf0001": fmt.Sprintf(concatInput720, "5bd37808534b441c4acf0001", "first", 30.5),
+		"5bd37808534b441c4acf0002": fmt.Sprintf(concatInput720, "5bd37808534b441c4acf0002", "second", 60),
+	}
+
+	var calls []string
+	handleConcat(t, inputs, &calls)
+
+	builder := NewConcatBuilder(client, "/matches", "match.mp4").Add("5bd37808534b441c4acf0001", "5bd37808534b441c4acf0002")
+	builder.PollInterval = time.Millisecond
+
+	object, plan, err := builder.Build(ctx)
+	if err != nil {
+		t.Fatalf("ConcatBuilder.Build returned error: %v", err)
+	}
+
+	if object.ID != "5bd37808534b441c4acfcccc" {
+		t.Errorf("ConcatBuilder.Build = %v, expected %v", object.ID, "5bd37808534b441c4acfcccc")
+	}
+
+	if plan.Duration != 90500*time.Millisecond || len(plan.Issues) != 0 {
+		t.Errorf("ConcatBuilder plan = %v %v, expected %v without issues", plan.Duration, plan.Issues, 90500*time.Millisecond)
+	}
+
+	expected := []string{"concat [5bd37808534b441c4acf0001 5bd37808534b441c4acf0002] /matches/match.mp4"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("ConcatBuilder calls = %v, expected %v", calls, expected)
+	}
+}
+
+func TestConcatBuilder_Incompatible(t *testing.T) {
+	setup()
+	defer teardown()
+
+	inputs := map[string]string{
+		"5bd37808534b441c4acf0001": fmt.Sprintf(concatInput720, "5bd37808534b441c4acf0001", "first", 30),
+		"5bd37808534b441c4acf0002": fmt.Sprintf(concatInput1080, "5bd37808534b441c4acf0002", "second", 60),
+	}
+
+	var calls []string
+	handleConcat(t, inputs, &calls)
+
+	builder := NewConcatBuilder(client, "/matches", "match.mp4").Add("5bd37808534b441c4acf0001", "5bd37808534b441c4acf0002")
+	builder.PollInterval = time.Millisecond
+
+	_, plan, err := builder.Build(ctx)
+	if err != ErrIncompatibleInputs {
+		t.Fatalf("ConcatBuilder.Build error = %v, expected %v", err, ErrIncompatibleInputs)
+	}
+
+	issues := make([]string, len(plan.Issues))
+	for i, issue := range plan.Issues {
+		issues[i] = issue.String()
+	}
+
+	expectedIssues := []string{
+		"5bd37808534b441c4acf0002: video codec is hevc, expected h264",
+		"5bd37808534b441c4acf0002: resolution is 1920x1080, expected 1280x720",
+		"5bd37808534b441c4acf0002: fps is 29.97, expected 25",
+		"5bd37808534b441c4acf0002: audio layout is mono, expected stereo",
+	}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("ConcatPlan.Issues = %v, expected %v", issues, expectedIssues)
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("ConcatBuilder calls = %v, expected none", calls)
+	}
+
+	builder.NormalizePreset = "566b0fbf044dfe64f2000003"
+	_, _, err = builder.Build(ctx)
+	if err != nil {
+		t.Fatalf("ConcatBuilder.Build returned error: %v", err)
+	}
+
+	expected := []string{
+		"transcode 5bd37808534b441c4acf0001 [566b0fbf044dfe64f2000003] /matches/.normalized/<run>/0",
+		"transcode 5bd37808534b441c4acf0002 [566b0fbf044dfe64f2000003] /matches/.normalized/<run>/1",
+		"concat [5bd37808534b441c4acfaa00 5bd37808534b441c4acfaa01] /matches/match.mp4",
+		"delete 5bd37808534b441c4acfaa00",
+		"delete 5bd37808534b441c4acfaa01",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("ConcatBuilder calls = %v, expected %v", calls, expected)
+	}
+}
+
+func TestConcatBuilder_BuildName(t *testing.T) {
+	inputs := map[string]string{
+		"5bd37808534b441c4acf0001": fmt.Sprintf(concatInput720, "5bd37808534b441c4acf0001", "first", 30),
+		"5bd37808534b441c4acf0002": fmt.Sprintf(concatInput720, "5bd37808534b441c4acf0002", "second", 60),
+	}
+
+	// API adds extension or names the result itself
+	for _, name := range []string{"highlights", ""} {
+		setup()
+
+		var calls []string
+		handleConcat(t, inputs, &calls)
+
+		builder := NewConcatBuilder(client, "/matches", name).Add("5bd37808534b441c4acf0001", "5bd37808534b441c4acf0002")
+		builder.PollInterval = time.Millisecond
+
+		object, _, err := builder.Build(ctx)
+		if err != nil || object.ID != "5bd37808534b441c4acfcccc" {
+			t.Errorf("ConcatBuilder.Build(%q) = %v, %v, expected %v", name, object, err, "5bd37808534b441c4acfcccc")
+		}
+
+		teardown()
+	}
+}
+
+func TestConcatBuilder_CheckInputs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := NewConcatBuilder(client, "/matches", "match.mp4").Add("5bd37808534b441c4acf0001").Check(ctx)
+	if err == nil {
+		t.Errorf("ConcatBuilder.Check expected error of a single input")
+	}
+}
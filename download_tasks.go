@@ -10,7 +10,7 @@ const downloadTasksBasePath = "/1/download_tasks"
 // DownloadTasksService implements interface with API /download_tasks endpoint.
 // See https://doc.platformcraft.ru/filespot/api/en/#download_tasks
 type DownloadTasksService interface {
-	List(context.Context) ([]Task, *http.Response, error)
+	List(context.Context, *TasksListParams) ([]Task, *http.Response, error)
 	Get(context.Context, string) (*Task, *http.Response, error)
 	Delete(context.Context, string) (*http.Response, error)
 }
@@ -32,6 +32,13 @@ type Task struct {
 	Lock       bool   `json:"lock"`
 }
 
+// Task statuses
+const (
+	TaskProgress  = "Progress"
+	TaskCompleted = "Completed"
+	TaskError     = "Error"
+)
+
 // tasksRoot represents a List root
 type tasksRoot struct {
 	Tasks []Task `json:"tasks"`
@@ -43,9 +50,21 @@ type taskRoot struct {
 	Task *Task `json:"task"`
 }
 
+// TasksListParams identifies as query params of tasks List request
+type TasksListParams struct {
+	// Status is one of TaskProgress, TaskCompleted and TaskError
+	Status   string `url:"status,omitempty"`
+	Category string `url:"category,omitempty"`
+}
+
 // List of Tasks
-func (c DownloadTasksCli) List(ctx context.Context) ([]Task, *http.Response, error) {
-	req, err := c.client.NewRequest(ctx, http.MethodGet, downloadTasksBasePath, nil)
+func (c DownloadTasksCli) List(ctx context.Context, params *TasksListParams) ([]Task, *http.Response, error) {
+	path, err := addParams(downloadTasksBasePath, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, nil, err
 	}
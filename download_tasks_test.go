@@ -69,7 +69,7 @@ func TestDownloadTasksList(t *testing.T) {
 		},
 	}
 
-	tasks, _, err := client.DownloadTasks.List(ctx)
+	tasks, _, err := client.DownloadTasks.List(ctx, nil)
 	if err != nil {
 		t.Errorf("DownloadTasks.List returned error: %v", err)
 	}
@@ -0,0 +1,288 @@
+package filespot
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEmbedWidth = 640
+	oEmbedVersion     = "1.0"
+	oEmbedProvider    = "platformcraft"
+)
+
+// Embed builds embed code of Player.
+// Zero fields are derived from the Player and its source Object:
+//
+//	embed := NewEmbed(player, object)
+//	embed.Responsive = true
+//	embed.Autoplay = true
+//	html := embed.HTML()
+type Embed struct {
+	Player *Player
+	// Object is the source of the player, its video stream gives dimensions
+	Object *Object
+
+	// Width and Height in pixels, missing one is computed from aspect ratio
+	Width  int
+	Height int
+	// AspectRatio is width divided by height, taken from the Object by default
+	AspectRatio float64
+	// Responsive makes embed fill the container keeping aspect ratio
+	Responsive bool
+
+	Autoplay bool
+	Muted    bool
+	// Start plays video from offset
+	Start time.Duration
+	// Poster URL, Player.ScreenShotURL by default
+	Poster string
+	// PosterWidth and PosterHeight are size of the poster image in pixels.
+	// Screenshots are frames of the video, so video size of the Object is used by default,
+	// and the embed size when the Object is unknown.
+	PosterWidth  int
+	PosterHeight int
+	// Title of iframe, Player.Name by default
+	Title string
+	// Scheme of player URL when Player.Href has none, https by default
+	Scheme string
+}
+
+// OEmbed represents oEmbed 1.0 response of video type.
+// See https://oembed.com
+type OEmbed struct {
+	XMLName         xml.Name `json:"-" xml:"oembed"`
+	Type            string   `json:"type" xml:"type"`
+	Version         string   `json:"version" xml:"version"`
+	Title           string   `json:"title,omitempty" xml:"title,omitempty"`
+	ProviderName    string   `json:"provider_name,omitempty" xml:"provider_name,omitempty"`
+	ProviderURL     string   `json:"provider_url,omitempty" xml:"provider_url,omitempty"`
+	ThumbnailURL    string   `json:"thumbnail_url,omitempty" xml:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int      `json:"thumbnail_width,omitempty" xml:"thumbnail_width,omitempty"`
+	ThumbnailHeight int      `json:"thumbnail_height,omitempty" xml:"thumbnail_height,omitempty"`
+	HTML            string   `json:"html" xml:"html"`
+	Width           int      `json:"width" xml:"width"`
+	Height          int      `json:"height" xml:"height"`
+}
+
+// NewEmbed returns Embed of player, object may be nil
+func NewEmbed(player *Player, object *Object) *Embed {
+	return &Embed{
+		Player: player,
+		Object: object,
+	}
+}
+
+// URL returns player URL with playback params autoplay, muted, start and poster.
+// The params aren't documented by platformcraft, so check the player honours them.
+func (e *Embed) URL() string {
+	href := absoluteURL(e.Player.Href, e.Scheme)
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	q := u.Query()
+	if e.Autoplay {
+		q.Set("autoplay", "1")
+	}
+	if e.Muted {
+		q.Set("muted", "1")
+	}
+	if e.Start > 0 {
+		q.Set("start", strconv.Itoa(int(e.Start/time.Second)))
+	}
+	if poster := e.poster(); poster != "" && poster != e.Player.ScreenShotURL {
+		q.Set("poster", poster)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// Size returns width and height of embed
+func (e *Embed) Size() (int, int) {
+	ratio := e.aspectRatio()
+
+	switch {
+	case e.Width > 0 && e.Height > 0:
+		return e.Width, e.Height
+	case e.Width > 0:
+		return e.Width, int(float64(e.Width)/ratio + 0.5)
+	case e.Height > 0:
+		return int(float64(e.Height)*ratio + 0.5), e.Height
+	}
+
+	if stream := e.videoStream(); stream != nil && stream.Width > 0 && stream.Height > 0 {
+		return int(stream.Width), int(stream.Height)
+	}
+
+	return defaultEmbedWidth, int(defaultEmbedWidth/ratio + 0.5)
+}
+
+// HTML returns iframe embed code.
+// Responsive embed is wrapped into a container keeping aspect ratio.
+func (e *Embed) HTML() string {
+	width, height := e.Size()
+
+	allow := "fullscreen"
+	if e.Autoplay {
+		allow = "autoplay; fullscreen"
+	}
+
+	if !e.Responsive {
+		return fmt.Sprintf(`<iframe src="%v" width="%d" height="%d" title="%v" frameborder="0" scrolling="no" allow="%v" allowfullscreen></iframe>`,
+			html.EscapeString(e.URL()), width, height, html.EscapeString(e.title()), allow)
+	}
+
+	padding := strconv.FormatFloat(float64(height)/float64(width)*100, 'f', 4, 64)
+	padding = strings.TrimRight(strings.TrimRight(padding, "0"), ".")
+
+	return fmt.Sprintf(`<div style="position:relative;width:100%%;height:0;padding-bottom:%v%%;">`+
+		`<iframe src="%v" title="%v" style="position:absolute;top:0;left:0;width:100%%;height:100%%;" frameborder="0" scrolling="no" allow="%v" allowfullscreen></iframe>`+
+		`</div>`,
+		padding, html.EscapeString(e.URL()), html.EscapeString(e.title()), allow)
+}
+
+// AMP returns amp-iframe embed code with poster placeholder
+func (e *Embed) AMP() string {
+	width, height := e.Size()
+
+	placeholder := ""
+	if poster := e.poster(); poster != "" {
+		placeholder = fmt.Sprintf(`<amp-img layout="fill" src="%v" placeholder></amp-img>`, html.EscapeString(absoluteURL(poster, e.Scheme)))
+	}
+
+	return fmt.Sprintf(`<amp-iframe src="%v" width="%d" height="%d" layout="responsive" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allowfullscreen>%v</amp-iframe>`,
+		html.EscapeString(e.URL()), width, height, placeholder)
+}
+
+// OEmbed returns oEmbed response of the Player.
+// Poster is the thumbnail, see PosterWidth and PosterHeight.
+func (e *Embed) OEmbed() *OEmbed {
+	width, height := e.Size()
+
+	o := &OEmbed{
+		Type:         "video",
+		Version:      oEmbedVersion,
+		Title:        e.title(),
+		ProviderName: oEmbedProvider,
+		HTML:         e.HTML(),
+		Width:        width,
+		Height:       height,
+	}
+
+	if poster := e.poster(); poster != "" {
+		o.ThumbnailURL = absoluteURL(poster, e.Scheme)
+		o.ThumbnailWidth, o.ThumbnailHeight = e.posterSize()
+	}
+
+	return o
+}
+
+// aspectRatio returns width to height ratio, 16:9 when unknown
+func (e *Embed) aspectRatio() float64 {
+	if e.AspectRatio > 0 {
+		return e.AspectRatio
+	}
+
+	if e.Width > 0 && e.Height > 0 {
+		return float64(e.Width) / float64(e.Height)
+	}
+
+	if stream := e.videoStream(); stream != nil {
+		if ratio := parseAspectRatio(stream.DisplayAspectRatio); ratio > 0 {
+			return ratio
+		}
+
+		if stream.Width > 0 && stream.Height > 0 {
+			return float64(stream.Width) / float64(stream.Height)
+		}
+	}
+
+	return 16.0 / 9.0
+}
+
+// videoStream returns the first video stream of Object
+func (e *Embed) videoStream() *ObjectVideoStream {
+	return objectVideoStream(e.Object)
+}
+
+// objectVideoStream returns the first video stream of object, nil when there is none
+func objectVideoStream(object *Object) *ObjectVideoStream {
+	if object == nil || object.Advanced == nil || len(object.Advanced.VideoStreams) == 0 {
+		return nil
+	}
+
+	return &object.Advanced.VideoStreams[0]
+}
+
+// poster returns poster URL
+func (e *Embed) poster() string {
+	if e.Poster != "" {
+		return e.Poster
+	}
+
+	return e.Player.ScreenShotURL
+}
+
+// posterSize returns size of poster
+func (e *Embed) posterSize() (int, int) {
+	if e.PosterWidth > 0 && e.PosterHeight > 0 {
+		return e.PosterWidth, e.PosterHeight
+	}
+
+	if stream := e.videoStream(); stream != nil && stream.Width > 0 && stream.Height > 0 {
+		return int(stream.Width), int(stream.Height)
+	}
+
+	return e.Size()
+}
+
+// title returns title of embed
+func (e *Embed) title() string {
+	if e.Title != "" {
+		return e.Title
+	}
+
+	return e.Player.Name
+}
+
+// parseAspectRatio parses ratio like "16:9"
+func parseAspectRatio(s string) float64 {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0
+	}
+
+	w, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+
+	h, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || h == 0 {
+		return 0
+	}
+
+	return w / h
+}
+
+// absoluteURL adds scheme to URL returned by API without one
+func absoluteURL(s, scheme string) string {
+	if s == "" || strings.Contains(s, "://") {
+		return s
+	}
+
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + s
+}
@@ -0,0 +1,132 @@
+package filespot
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	embedTestPlayer = &Player{
+		ID:            "567d3643534b4474087c221e",
+		Name:          "player_name_1",
+		ScreenShotURL: "cdn.platformcraft.ru/alex/example.jpg",
+		Href:          "video.platformcraft.ru/embed/567d3643534b4474087c221e",
+	}
+
+	embedTestObject = &Object{
+		ID: "56787f0c044dfe226b000001",
+		Advanced: &ObjectAdvanced{
+			VideoStreams: []ObjectVideoStream{
+				{Width: 1280, Height: 720, DisplayAspectRatio: "16:9"},
+			},
+		},
+	}
+)
+
+func TestEmbedHTML(t *testing.T) {
+	embed := NewEmbed(embedTestPlayer, embedTestObject)
+	embed.Autoplay = true
+	embed.Muted = true
+	embed.Start = 90 * time.Second
+
+	expected := `<iframe src="https://video.platformcraft.ru/embed/567d3643534b4474087c221e?autoplay=1&amp;muted=1&amp;start=90" ` +
+		`width="1280" height="720" title="player_name_1" frameborder="0" scrolling="no" allow="autoplay; fullscreen" allowfullscreen></iframe>`
+
+	if html := embed.HTML(); html != expected {
+		t.Errorf("Embed.HTML = %v, expected %v", html, expected)
+	}
+}
+
+func TestEmbedSize(t *testing.T) {
+	tests := []struct {
+		width, height  int
+		object         *Object
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{0, 0, embedTestObject, 1280, 720},
+		{640, 0, embedTestObject, 640, 360},
+		{0, 480, embedTestObject, 853, 480},
+		{0, 0, nil, 640, 360},
+		{400, 300, nil, 400, 300},
+	}
+
+	for _, tt := range tests {
+		embed := NewEmbed(embedTestPlayer, tt.object)
+		embed.Width, embed.Height = tt.width, tt.height
+
+		w, h := embed.Size()
+		if w != tt.expectedWidth || h != tt.expectedHeight {
+			t.Errorf("Embed.Size(%v, %v) = %v, %v, expected %v, %v", tt.width, tt.height, w, h, tt.expectedWidth, tt.expectedHeight)
+		}
+	}
+}
+
+func TestEmbedResponsive(t *testing.T) {
+	embed := NewEmbed(embedTestPlayer, embedTestObject)
+	embed.Responsive = true
+
+	html := embed.HTML()
+	if !strings.Contains(html, "padding-bottom:56.25%;") || strings.Contains(html, `width="`) {
+		t.Errorf("Embed.HTML = %v, expected responsive 16:9 container", html)
+	}
+}
+
+func TestEmbedAMP(t *testing.T) {
+	embed := NewEmbed(embedTestPlayer, embedTestObject)
+
+	expected := `<amp-iframe src="https://video.platformcraft.ru/embed/567d3643534b4474087c221e" width="1280" height="720" layout="responsive" ` +
+		`sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allowfullscreen>` +
+		`<amp-img layout="fill" src="https://cdn.platformcraft.ru/alex/example.jpg" placeholder></amp-img></amp-iframe>`
+
+	if amp := embed.AMP(); amp != expected {
+		t.Errorf("Embed.AMP = %v, expected %v", amp, expected)
+	}
+}
+
+func TestEmbedOEmbed(t *testing.T) {
+	embed := NewEmbed(embedTestPlayer, embedTestObject)
+	embed.Width = 640
+
+	b, err := json.Marshal(embed.OEmbed())
+	if err != nil {
+		t.Errorf("json.Marshal returned error: %v", err)
+	}
+
+	o := make(map[string]interface{})
+	json.Unmarshal(b, &o)
+
+	expected := map[string]interface{}{
+		"type":    "video",
+		"version": "1.0",
+		"title":   "player_name_1",
+		"width":   float64(640),
+		"height":  float64(360),
+	}
+
+	for k, v := range expected {
+		if o[k] != v {
+			t.Errorf("OEmbed %v = %v, expected %v", k, o[k], v)
+		}
+	}
+
+	// poster is a frame of the video
+	if o["thumbnail_url"] != "https://cdn.platformcraft.ru/alex/example.jpg" || o["thumbnail_width"] != float64(1280) || o["thumbnail_height"] != float64(720) {
+		t.Errorf("OEmbed thumbnail = %v %vx%v, expected poster of 1280x720", o["thumbnail_url"], o["thumbnail_width"], o["thumbnail_height"])
+	}
+
+	embed.PosterWidth, embed.PosterHeight = 320, 180
+	thumbnail := embed.OEmbed()
+	if thumbnail.ThumbnailWidth != 320 || thumbnail.ThumbnailHeight != 180 {
+		t.Errorf("OEmbed thumbnail = %vx%v, expected 320x180", thumbnail.ThumbnailWidth, thumbnail.ThumbnailHeight)
+	}
+
+	embed = NewEmbed(embedTestPlayer, nil)
+	embed.Width, embed.Height = 640, 360
+	thumbnail = embed.OEmbed()
+	if thumbnail.ThumbnailWidth != 640 || thumbnail.ThumbnailHeight != 360 {
+		t.Errorf("OEmbed thumbnail = %vx%v, expected embed size 640x360", thumbnail.ThumbnailWidth, thumbnail.ThumbnailHeight)
+	}
+}
@@ -3,11 +3,9 @@ package filespot
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -34,6 +32,20 @@ type Client struct {
 	APIUserID  string
 	APIUserKey string
 
+	// Logger receives a record for every request when set.
+	// Credentials are never logged.
+	Logger *slog.Logger
+	// LogBodies enables request and response body dumps at debug level
+	LogBodies bool
+	// LogBodyLimit truncates dumped bodies, 2048 bytes by default
+	LogBodyLimit int
+
+	// Observer is notified about every request when set
+	Observer RequestObserver
+
+	// skew is the server clock offset in nanoseconds used for signing
+	skew int64
+
 	// Services provides communication with API endpoints
 	Objects         ObjectsService
 	Temp            TempService
@@ -83,19 +95,25 @@ func NewClient(apiUserID, apiUserKey string) *Client {
 	return c
 }
 
+// HTTPClient returns http.Client used to send requests
+func (c *Client) HTTPClient() *http.Client {
+	return c.client
+}
+
+// SetHTTPClient replaces http.Client used to send requests
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.client = httpClient
+}
+
 // generateHash returns HMAC hash-sum for authentication
 func (c *Client) generateHash(method, path, timestamp string) string {
-	data := fmt.Sprintf("%v+%v%v?apiuserid=%v&timestamp=%v", method, c.BaseURL.Host, path, c.APIUserID, timestamp)
-	mac := hmac.New(sha256.New, []byte(c.APIUserKey))
-	mac.Write([]byte(data))
-
-	return hex.EncodeToString(mac.Sum(nil))
+	return NewSigner(c.APIUserID, c.APIUserKey).Sign(method, c.BaseURL.Host, path, timestamp)
 }
 
 // requestURL returns URL with formated request
 func (c *Client) requestURL(method, endpointURL string) *url.URL {
 	endpoint, _ := url.Parse(endpointURL)
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	timestamp := strconv.FormatInt(c.now().Unix(), 10)
 	hash := c.generateHash(method, endpoint.Path, timestamp)
 
 	q := endpoint.Query()
@@ -136,10 +154,40 @@ func DoClientRequest(ctx context.Context, c *Client, req *http.Request) (*http.R
 	return c.client.Do(req)
 }
 
-// Do sends request and returns API response
+// Do sends request and returns API response.
+// Request rejected due to clock skew is signed again and retried once.
 func (c *Client) Do(ctx context.Context, req *http.Request, data interface{}) (*http.Response, error) {
+	c.logRequestBody(ctx, req)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(ctx, req, data, attempt)
+		if attempt >= maxAttempts || !c.adjustSkew(ctx, resp, err) {
+			return resp, err
+		}
+
+		retry, rerr := c.resignRequest(req)
+		if rerr != nil {
+			return resp, err
+		}
+		req = retry
+	}
+}
+
+// do sends a single request attempt
+func (c *Client) do(ctx context.Context, req *http.Request, data interface{}, attempt int) (*http.Response, error) {
+	info := &RequestInfo{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Endpoint: endpointPattern(req.URL.Path),
+		Attempt:  attempt,
+	}
+
+	start := time.Now()
 	resp, err := DoClientRequest(ctx, c, req)
+	info.Latency = time.Since(start)
 	if err != nil {
+		info.Err = err
+		c.finishRequest(ctx, req, info)
 		return nil, err
 	}
 
@@ -147,16 +195,27 @@ func (c *Client) Do(ctx context.Context, req *http.Request, data interface{}) (*
 		resp.Body.Close()
 	}()
 
+	info.Status = resp.StatusCode
+	c.logResponseBody(ctx, resp)
+
 	err = CheckResponse(resp)
 	if err != nil {
+		info.Err = err
+		if errorResponse, ok := err.(*ErrorResponse); ok {
+			info.ErrorCode = errorResponse.Code
+		}
+		c.finishRequest(ctx, req, info)
 		return resp, err
 	}
 
 	err = json.NewDecoder(resp.Body).Decode(data)
 	if err != nil {
+		info.Err = err
+		c.finishRequest(ctx, req, info)
 		return nil, err
 	}
 
+	c.finishRequest(ctx, req, info)
 	return resp, err
 }
 
@@ -204,6 +263,6 @@ func addParams(path string, params interface{}) (string, error) {
 		newPath[k] = v
 	}
 
-	pathURL.RawQuery = newParams.Encode()
+	pathURL.RawQuery = newPath.Encode()
 	return pathURL.String(), nil
 }
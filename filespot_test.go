@@ -170,3 +170,33 @@ func TestAddParams(t *testing.T) {
 		t.Errorf("addParams = %v, expected %v", path, expected)
 	}
 }
+
+func TestAddListParams(t *testing.T) {
+	tests := []struct {
+		path     string
+		params   interface{}
+		expected string
+	}{
+		{objectsBasePath, (*ObjectsListParams)(nil), "/1/objects"},
+		{objectsBasePath, &ObjectsListParams{Ext: "mp4", Private: true, Limit: 10}, "/1/objects?ext=mp4&limit=10&private=true"},
+		{playersBasePath, &PlayersListParams{}, "/1/players"},
+		{playersBasePath, &PlayersListParams{Folder: "/shows", Name: "pilot", Tags: []string{"news", "sport"}, Start: 20, Limit: 10, Pagingts: 1525858519},
+			"/1/players?folder=%2Fshows&limit=10&name=pilot&pagingts=1525858519&start=20&tags=news%2Csport"},
+		{tempBasePath, &TempListParams{ObjectID: "58d51270534b440de6b0d075", ForSale: true, Secure: true},
+			"/1/temp?for_sale=true&object_id=58d51270534b440de6b0d075&secure=true"},
+		{downloadTasksBasePath, &TasksListParams{Status: TaskError}, "/1/download_tasks?status=Error"},
+		{transcoderTasksBasePath, &TasksListParams{Status: TaskProgress, Category: "transcoder"}, "/1/transcoder_tasks?category=transcoder&status=Progress"},
+		{"/1/objects?limit=5", &ObjectsListParams{Name: "a"}, "/1/objects?limit=5&name=a"},
+	}
+
+	for _, tt := range tests {
+		path, err := addParams(tt.path, tt.params)
+		if err != nil {
+			t.Errorf("addParams returned error = %v", err)
+		}
+
+		if path != tt.expected {
+			t.Errorf("addParams = %v, expected %v", path, tt.expected)
+		}
+	}
+}
@@ -1,24 +1,161 @@
 package filespot
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Geo represents platformcraft `geo` type
 // It's a map of `map[continent]map[country]true`
 // Keys `continent` and `country` are ISO codes of continents and counties (e.g, EU и RU).
 // All keys MUST be capitalized.
-// When `country` was set to "ALL" it grants access to whole world.
+// When `country` was set to "ALL" it grants access to whole continent,
+// and `{"ALL": {"ALL": true}}` grants access to whole world.
 // For example:
-//      grants acccess to Europe and North America
-//        &Geo{
-//            "EU": {
-//                "ALL": true,
-//            },
-//            "NA": {
-//                "ALL": true,
-//            },
-//        }
-//      grants access only to Russia
-//        &Geo{
-//            "EU": {
-//                "RU": true,
-//            },
-//        }
+//
+//	grants acccess to Europe and North America
+//	  &Geo{
+//	      "EU": {
+//	          "ALL": true,
+//	      },
+//	      "NA": {
+//	          "ALL": true,
+//	      },
+//	  }
+//	grants access only to Russia
+//	  &Geo{
+//	      "EU": {
+//	          "RU": true,
+//	      },
+//	  }
 type Geo map[string]map[string]bool
+
+// GeoAll grants access to all countries of continent
+const GeoAll = "ALL"
+
+// GeoError describes invalid Geo key
+type GeoError struct {
+	Continent string
+	Country   string
+	Reason    string
+}
+
+// Error returns formated error
+func (e *GeoError) Error() string {
+	if e.Country == "" {
+		return fmt.Sprintf("filespot: geo continent %q: %v", e.Continent, e.Reason)
+	}
+
+	return fmt.Sprintf("filespot: geo country %q of %q: %v", e.Country, e.Continent, e.Reason)
+}
+
+// NewGeo returns empty Geo to be filled with Allow methods:
+//
+//	geo := NewGeo().AllowContinent("NA").AllowCountry("DE")
+func NewGeo() Geo {
+	return make(Geo)
+}
+
+// AllowContinent grants access to whole continent
+func (g Geo) AllowContinent(continent string) Geo {
+	continent = strings.ToUpper(continent)
+	g[continent] = map[string]bool{GeoAll: true}
+
+	return g
+}
+
+// AllowCountry grants access to country, its continent is filled in from ISO 3166 table.
+// Unknown countries are kept under empty continent and rejected by Validate.
+func (g Geo) AllowCountry(country string) Geo {
+	country = strings.ToUpper(country)
+	continent := countryContinents[country]
+
+	if g[continent][GeoAll] || g[GeoAll][GeoAll] {
+		return g
+	}
+
+	if g[continent] == nil {
+		g[continent] = make(map[string]bool)
+	}
+	g[continent][country] = true
+
+	return g
+}
+
+// AllowWorld grants access to all continents
+func (g Geo) AllowWorld() Geo {
+	for continent := range continents {
+		g.AllowContinent(continent)
+	}
+
+	return g
+}
+
+// Validate checks that Geo keys are capitalized ISO codes of continents and their countries.
+// Continent "ALL" is the whole world and accepts only "ALL" country.
+func (g Geo) Validate() error {
+	for continent, countries := range g {
+		if continent == GeoAll {
+			for country := range countries {
+				if country != GeoAll {
+					return &GeoError{Continent: continent, Country: country, Reason: "world accepts only ALL"}
+				}
+			}
+			continue
+		}
+
+		if _, ok := continents[continent]; !ok && continent != "" {
+			return &GeoError{Continent: continent, Reason: geoReason(continent, continents, "unknown continent")}
+		}
+
+		for country := range countries {
+			if country == GeoAll {
+				continue
+			}
+
+			c, ok := countryContinents[country]
+			if !ok {
+				return &GeoError{Continent: continent, Country: country, Reason: geoReason(country, countryContinents, "unknown country")}
+			}
+
+			if c != continent && transcontinental[country] != continent {
+				return &GeoError{Continent: continent, Country: country, Reason: "country belongs to " + c}
+			}
+		}
+	}
+
+	return nil
+}
+
+// geoReason explains why code is missing in table
+func geoReason(code string, table map[string]string, reason string) string {
+	if _, ok := table[strings.ToUpper(code)]; ok {
+		return "code must be capitalized"
+	}
+
+	return reason
+}
+
+// grantsWorld reports whether Geo grants access to all continents
+func (g Geo) grantsWorld() bool {
+	if g[GeoAll][GeoAll] {
+		return true
+	}
+
+	for continent := range continents {
+		if !g[continent][GeoAll] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateGeo validates geo of requests, nil Geo is valid
+func validateGeo(geo Geo) error {
+	if geo == nil {
+		return nil
+	}
+
+	return geo.Validate()
+}
@@ -0,0 +1,277 @@
+package filespot
+
+// continents are ISO codes of continents used as Geo keys
+var continents = map[string]string{
+	"AF": "Africa",
+	"AN": "Antarctica",
+	"AS": "Asia",
+	"EU": "Europe",
+	"NA": "North America",
+	"OC": "Oceania",
+	"SA": "South America",
+}
+
+// countryContinents maps ISO 3166-1 alpha-2 country codes to their continents
+var countryContinents = map[string]string{
+	"AD": "EU", // Andorra
+	"AE": "AS", // United Arab Emirates
+	"AF": "AS", // Afghanistan
+	"AG": "NA", // Antigua & Barbuda
+	"AI": "NA", // Anguilla
+	"AL": "EU", // Albania
+	"AM": "AS", // Armenia
+	"AO": "AF", // Angola
+	"AQ": "AN", // Antarctica
+	"AR": "SA", // Argentina
+	"AS": "OC", // Samoa (American)
+	"AT": "EU", // Austria
+	"AU": "OC", // Australia
+	"AW": "NA", // Aruba
+	"AX": "EU", // Åland Islands
+	"AZ": "AS", // Azerbaijan
+	"BA": "EU", // Bosnia & Herzegovina
+	"BB": "NA", // Barbados
+	"BD": "AS", // Bangladesh
+	"BE": "EU", // Belgium
+	"BF": "AF", // Burkina Faso
+	"BG": "EU", // Bulgaria
+	"BH": "AS", // Bahrain
+	"BI": "AF", // Burundi
+	"BJ": "AF", // Benin
+	"BL": "NA", // St Barthelemy
+	"BM": "NA", // Bermuda
+	"BN": "AS", // Brunei
+	"BO": "SA", // Bolivia
+	"BQ": "NA", // Caribbean NL
+	"BR": "SA", // Brazil
+	"BS": "NA", // Bahamas
+	"BT": "AS", // Bhutan
+	"BV": "AN", // Bouvet Island
+	"BW": "AF", // Botswana
+	"BY": "EU", // Belarus
+	"BZ": "NA", // Belize
+	"CA": "NA", // Canada
+	"CC": "AS", // Cocos (Keeling) Islands
+	"CD": "AF", // Congo (Dem. Rep.)
+	"CF": "AF", // Central African Rep.
+	"CG": "AF", // Congo (Rep.)
+	"CH": "EU", // Switzerland
+	"CI": "AF", // Côte d'Ivoire
+	"CK": "OC", // Cook Islands
+	"CL": "SA", // Chile
+	"CM": "AF", // Cameroon
+	"CN": "AS", // China
+	"CO": "SA", // Colombia
+	"CR": "NA", // Costa Rica
+	"CU": "NA", // Cuba
+	"CV": "AF", // Cape Verde
+	"CW": "NA", // Curaçao
+	"CX": "AS", // Christmas Island
+	"CY": "AS", // Cyprus
+	"CZ": "EU", // Czech Republic
+	"DE": "EU", // Germany
+	"DJ": "AF", // Djibouti
+	"DK": "EU", // Denmark
+	"DM": "NA", // Dominica
+	"DO": "NA", // Dominican Republic
+	"DZ": "AF", // Algeria
+	"EC": "SA", // Ecuador
+	"EE": "EU", // Estonia
+	"EG": "AF", // Egypt
+	"EH": "AF", // Western Sahara
+	"ER": "AF", // Eritrea
+	"ES": "EU", // Spain
+	"ET": "AF", // Ethiopia
+	"FI": "EU", // Finland
+	"FJ": "OC", // Fiji
+	"FK": "SA", // Falkland Islands
+	"FM": "OC", // Micronesia
+	"FO": "EU", // Faroe Islands
+	"FR": "EU", // France
+	"GA": "AF", // Gabon
+	"GB": "EU", // Britain (UK)
+	"GD": "NA", // Grenada
+	"GE": "AS", // Georgia
+	"GF": "SA", // French Guiana
+	"GG": "EU", // Guernsey
+	"GH": "AF", // Ghana
+	"GI": "EU", // Gibraltar
+	"GL": "NA", // Greenland
+	"GM": "AF", // Gambia
+	"GN": "AF", // Guinea
+	"GP": "NA", // Guadeloupe
+	"GQ": "AF", // Equatorial Guinea
+	"GR": "EU", // Greece
+	"GS": "AN", // South Georgia & the South Sandwich Islands
+	"GT": "NA", // Guatemala
+	"GU": "OC", // Guam
+	"GW": "AF", // Guinea-Bissau
+	"GY": "SA", // Guyana
+	"HK": "AS", // Hong Kong
+	"HM": "AN", // Heard Island & McDonald Islands
+	"HN": "NA", // Honduras
+	"HR": "EU", // Croatia
+	"HT": "NA", // Haiti
+	"HU": "EU", // Hungary
+	"ID": "AS", // Indonesia
+	"IE": "EU", // Ireland
+	"IL": "AS", // Israel
+	"IM": "EU", // Isle of Man
+	"IN": "AS", // India
+	"IO": "AS", // British Indian Ocean Territory
+	"IQ": "AS", // Iraq
+	"IR": "AS", // Iran
+	"IS": "EU", // Iceland
+	"IT": "EU", // Italy
+	"JE": "EU", // Jersey
+	"JM": "NA", // Jamaica
+	"JO": "AS", // Jordan
+	"JP": "AS", // Japan
+	"KE": "AF", // Kenya
+	"KG": "AS", // Kyrgyzstan
+	"KH": "AS", // Cambodia
+	"KI": "OC", // Kiribati
+	"KM": "AF", // Comoros
+	"KN": "NA", // St Kitts & Nevis
+	"KP": "AS", // Korea (North)
+	"KR": "AS", // Korea (South)
+	"KW": "AS", // Kuwait
+	"KY": "NA", // Cayman Islands
+	"KZ": "AS", // Kazakhstan
+	"LA": "AS", // Laos
+	"LB": "AS", // Lebanon
+	"LC": "NA", // St Lucia
+	"LI": "EU", // Liechtenstein
+	"LK": "AS", // Sri Lanka
+	"LR": "AF", // Liberia
+	"LS": "AF", // Lesotho
+	"LT": "EU", // Lithuania
+	"LU": "EU", // Luxembourg
+	"LV": "EU", // Latvia
+	"LY": "AF", // Libya
+	"MA": "AF", // Morocco
+	"MC": "EU", // Monaco
+	"MD": "EU", // Moldova
+	"ME": "EU", // Montenegro
+	"MF": "NA", // St Martin (French)
+	"MG": "AF", // Madagascar
+	"MH": "OC", // Marshall Islands
+	"MK": "EU", // North Macedonia
+	"ML": "AF", // Mali
+	"MM": "AS", // Myanmar (Burma)
+	"MN": "AS", // Mongolia
+	"MO": "AS", // Macau
+	"MP": "OC", // Northern Mariana Islands
+	"MQ": "NA", // Martinique
+	"MR": "AF", // Mauritania
+	"MS": "NA", // Montserrat
+	"MT": "EU", // Malta
+	"MU": "AF", // Mauritius
+	"MV": "AS", // Maldives
+	"MW": "AF", // Malawi
+	"MX": "NA", // Mexico
+	"MY": "AS", // Malaysia
+	"MZ": "AF", // Mozambique
+	"NA": "AF", // Namibia
+	"NC": "OC", // New Caledonia
+	"NE": "AF", // Niger
+	"NF": "OC", // Norfolk Island
+	"NG": "AF", // Nigeria
+	"NI": "NA", // Nicaragua
+	"NL": "EU", // Netherlands
+	"NO": "EU", // Norway
+	"NP": "AS", // Nepal
+	"NR": "OC", // Nauru
+	"NU": "OC", // Niue
+	"NZ": "OC", // New Zealand
+	"OM": "AS", // Oman
+	"PA": "NA", // Panama
+	"PE": "SA", // Peru
+	"PF": "OC", // French Polynesia
+	"PG": "OC", // Papua New Guinea
+	"PH": "AS", // Philippines
+	"PK": "AS", // Pakistan
+	"PL": "EU", // Poland
+	"PM": "NA", // St Pierre & Miquelon
+	"PN": "OC", // Pitcairn
+	"PR": "NA", // Puerto Rico
+	"PS": "AS", // Palestine
+	"PT": "EU", // Portugal
+	"PW": "OC", // Palau
+	"PY": "SA", // Paraguay
+	"QA": "AS", // Qatar
+	"RE": "AF", // Réunion
+	"RO": "EU", // Romania
+	"RS": "EU", // Serbia
+	"RU": "EU", // Russia
+	"RW": "AF", // Rwanda
+	"SA": "AS", // Saudi Arabia
+	"SB": "OC", // Solomon Islands
+	"SC": "AF", // Seychelles
+	"SD": "AF", // Sudan
+	"SE": "EU", // Sweden
+	"SG": "AS", // Singapore
+	"SH": "AF", // St Helena
+	"SI": "EU", // Slovenia
+	"SJ": "EU", // Svalbard & Jan Mayen
+	"SK": "EU", // Slovakia
+	"SL": "AF", // Sierra Leone
+	"SM": "EU", // San Marino
+	"SN": "AF", // Senegal
+	"SO": "AF", // Somalia
+	"SR": "SA", // Suriname
+	"SS": "AF", // South Sudan
+	"ST": "AF", // Sao Tome & Principe
+	"SV": "NA", // El Salvador
+	"SX": "NA", // St Maarten (Dutch)
+	"SY": "AS", // Syria
+	"SZ": "AF", // Eswatini (Swaziland)
+	"TC": "NA", // Turks & Caicos Is
+	"TD": "AF", // Chad
+	"TF": "AN", // French S. Terr.
+	"TG": "AF", // Togo
+	"TH": "AS", // Thailand
+	"TJ": "AS", // Tajikistan
+	"TK": "OC", // Tokelau
+	"TL": "AS", // East Timor
+	"TM": "AS", // Turkmenistan
+	"TN": "AF", // Tunisia
+	"TO": "OC", // Tonga
+	"TR": "AS", // Turkey
+	"TT": "NA", // Trinidad & Tobago
+	"TV": "OC", // Tuvalu
+	"TW": "AS", // Taiwan
+	"TZ": "AF", // Tanzania
+	"UA": "EU", // Ukraine
+	"UG": "AF", // Uganda
+	"UM": "NA", // US minor outlying islands
+	"US": "NA", // United States
+	"UY": "SA", // Uruguay
+	"UZ": "AS", // Uzbekistan
+	"VA": "EU", // Vatican City
+	"VC": "NA", // St Vincent
+	"VE": "SA", // Venezuela
+	"VG": "NA", // Virgin Islands (UK)
+	"VI": "NA", // Virgin Islands (US)
+	"VN": "AS", // Vietnam
+	"VU": "OC", // Vanuatu
+	"WF": "OC", // Wallis & Futuna
+	"WS": "OC", // Samoa (western)
+	"YE": "AS", // Yemen
+	"YT": "AF", // Mayotte
+	"ZA": "AF", // South Africa
+	"ZM": "AF", // Zambia
+	"ZW": "AF", // Zimbabwe
+}
+
+// transcontinental are countries allowed under a second continent, e.g. &Geo{"AS": {"RU": true}}
+var transcontinental = map[string]string{
+	"AM": "EU",
+	"AZ": "EU",
+	"CY": "EU",
+	"EG": "AS",
+	"GE": "EU",
+	"KZ": "EU",
+	"RU": "AS",
+	"TR": "EU",
+}
@@ -0,0 +1,180 @@
+package filespot
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Allows reports whether a viewer from country has access.
+// Empty Geo doesn't restrict access and allows every country.
+func (g Geo) Allows(country string) bool {
+	if len(g) == 0 || g[GeoAll][GeoAll] {
+		return true
+	}
+
+	country = strings.ToUpper(country)
+	for _, continent := range []string{countryContinents[country], transcontinental[country]} {
+		if continent == "" {
+			continue
+		}
+
+		if g[continent][GeoAll] || g[continent][country] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Union returns Geo granting access to countries of g or other.
+// Set operations treat Geo as the set of countries it Allows, so empty Geo is the whole world
+// and "ALL" of continent includes its transcontinental countries. Result granting no country
+// lists every continent without countries, since empty Geo doesn't restrict access.
+func (g Geo) Union(other Geo) Geo {
+	a, b := g.countries(), other.countries()
+	for country := range b {
+		a[country] = true
+	}
+
+	return geoFromCountries(a)
+}
+
+// Intersect returns Geo granting access to countries of both g and other
+func (g Geo) Intersect(other Geo) Geo {
+	a, b := g.countries(), other.countries()
+	for country := range a {
+		if !b[country] {
+			delete(a, country)
+		}
+	}
+
+	return geoFromCountries(a)
+}
+
+// Subtract returns Geo granting access to countries of g which are not in other
+func (g Geo) Subtract(other Geo) Geo {
+	a, b := g.countries(), other.countries()
+	for country := range b {
+		delete(a, country)
+	}
+
+	return geoFromCountries(a)
+}
+
+// Equal reports whether g and other grant access to the same countries
+func (g Geo) Equal(other Geo) bool {
+	a, b := g.countries(), other.countries()
+	if len(a) != len(b) {
+		return false
+	}
+
+	for country := range a {
+		if !b[country] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Canonical returns equal Geo where countries are kept under their primary continent,
+// continents with all countries granted are collapsed into "ALL" and countries granted
+// by "ALL" of their second continent are omitted
+func (g Geo) Canonical() Geo {
+	return geoFromCountries(g.countries())
+}
+
+// CanonicalJSON returns JSON of canonical Geo with sorted keys
+func (g Geo) CanonicalJSON() ([]byte, error) {
+	return json.Marshal(g.Canonical())
+}
+
+// String returns canonical text form of Geo, e.g. "EU:ALL NA:CA,US".
+// Geo granting the whole world is "ALL".
+func (g Geo) String() string {
+	canonical := g.Canonical()
+	if len(canonical) > 0 && canonical.grantsWorld() {
+		return GeoAll
+	}
+
+	parts := make([]string, 0, len(canonical))
+	for continent, countries := range canonical {
+		if len(countries) == 0 {
+			continue
+		}
+
+		codes := make([]string, 0, len(countries))
+		for country := range countries {
+			codes = append(codes, country)
+		}
+		sort.Strings(codes)
+
+		parts = append(parts, continent+":"+strings.Join(codes, ","))
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, " ")
+}
+
+// countries returns set of countries Geo allows
+func (g Geo) countries() map[string]bool {
+	set := make(map[string]bool)
+
+	for country := range countryContinents {
+		if g.Allows(country) {
+			set[country] = true
+		}
+	}
+
+	return set
+}
+
+// geoFromCountries returns canonical Geo of countries set
+func geoFromCountries(set map[string]bool) Geo {
+	g := NewGeo()
+	if len(set) == 0 {
+		for continent := range continents {
+			g[continent] = make(map[string]bool)
+		}
+
+		return g
+	}
+
+	for country := range set {
+		g.AllowCountry(country)
+	}
+
+	for continent := range continents {
+		if len(g[continent]) == continentSize(continent) {
+			g.AllowContinent(continent)
+		}
+	}
+
+	// countries granted by "ALL" of their second continent
+	for continent, countries := range g {
+		for country := range countries {
+			if other := transcontinental[country]; other != continent && g[other][GeoAll] {
+				delete(countries, country)
+			}
+		}
+
+		if len(countries) == 0 {
+			delete(g, continent)
+		}
+	}
+
+	return g
+}
+
+// continentSize returns number of countries of continent
+func continentSize(continent string) int {
+	n := 0
+	for _, c := range countryContinents {
+		if c == continent {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,131 @@
+package filespot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeoAllows(t *testing.T) {
+	geo := Geo{
+		"NA": {"ALL": true},
+		"EU": {"DE": true, "FR": false},
+		"AS": {"RU": true},
+	}
+
+	tests := map[string]bool{
+		"US": true,
+		"de": true,
+		"FR": false,
+		"RU": true,
+		"CN": false,
+		"ZZ": false,
+	}
+
+	for country, expected := range tests {
+		if got := geo.Allows(country); got != expected {
+			t.Errorf("Geo.Allows(%v) = %v, expected %v", country, got, expected)
+		}
+	}
+
+	if !(Geo{}).Allows("CN") {
+		t.Error("empty Geo.Allows = false, expected true")
+	}
+}
+
+func TestGeoSetAlgebra(t *testing.T) {
+	europe := NewGeo().AllowContinent("EU")
+	some := NewGeo().AllowCountry("DE").AllowCountry("US")
+
+	union := europe.Union(some)
+	if union.String() != "EU:ALL NA:US" {
+		t.Errorf("Geo.Union = %v, expected %v", union, "EU:ALL NA:US")
+	}
+
+	intersect := europe.Intersect(some)
+	if !reflect.DeepEqual(intersect, Geo{"EU": {"DE": true}}) {
+		t.Errorf("Geo.Intersect = %v, expected %v", intersect, "EU:DE")
+	}
+
+	subtract := some.Subtract(europe)
+	if !reflect.DeepEqual(subtract, Geo{"NA": {"US": true}}) {
+		t.Errorf("Geo.Subtract = %v, expected %v", subtract, "NA:US")
+	}
+
+	// Europe without Germany plus Germany is Europe again
+	if !europe.Subtract(some).Union(some).Equal(union) {
+		t.Errorf("Geo.Equal = false, expected true")
+	}
+
+	if europe.Equal(some) {
+		t.Errorf("Geo.Equal = true, expected false")
+	}
+}
+
+func TestGeoSetAllowAll(t *testing.T) {
+	ru := NewGeo().AllowCountry("RU")
+
+	// empty Geo doesn't restrict access, so it's the whole world
+	if !(Geo{}).Equal(NewGeo().AllowWorld()) {
+		t.Errorf("empty Geo.Equal(world) = false, expected true")
+	}
+
+	world := Geo{"ALL": {"ALL": true}}
+	if !world.Allows("CN") || !world.Equal(Geo{}) || world.String() != GeoAll {
+		t.Errorf("Geo(%v) = %v, expected the whole world", world, world.String())
+	}
+
+	if union := (Geo{}).Union(ru); union.String() != GeoAll {
+		t.Errorf("empty Geo.Union = %v, expected %v", union, GeoAll)
+	}
+
+	if intersect := (Geo{}).Intersect(ru); !intersect.Equal(ru) {
+		t.Errorf("empty Geo.Intersect = %v, expected %v", intersect, ru)
+	}
+
+	// Russia is granted by Asia as Allows does
+	intersect := Geo{"AS": {"ALL": true}}.Intersect(Geo{"EU": {"RU": true}})
+	if !reflect.DeepEqual(intersect, Geo{"EU": {"RU": true}}) {
+		t.Errorf("Geo.Intersect = %v, expected %v", intersect, "EU:RU")
+	}
+
+	none := ru.Subtract(Geo{})
+	if len(none) == 0 || none.Allows("RU") || none.Allows("US") || none.String() != "" {
+		t.Errorf("Geo.Subtract = %v, expected Geo denying every country", none)
+	}
+
+	if none.Equal(Geo{}) || none.Validate() != nil {
+		t.Errorf("Geo.Subtract = %v, expected valid Geo not equal to empty one", none)
+	}
+}
+
+func TestGeoCanonical(t *testing.T) {
+	// all countries of Oceania listed one by one and Russia under Asia
+	geo := Geo{"AS": {"RU": true}}
+	for country, continent := range countryContinents {
+		if continent == "OC" {
+			geo.AllowCountry(country)
+		}
+	}
+
+	expected := Geo{
+		"EU": {"RU": true},
+		"OC": {"ALL": true},
+	}
+
+	if !reflect.DeepEqual(geo.Canonical(), expected) {
+		t.Errorf("Geo.Canonical = %v, expected %v", geo.Canonical(), expected)
+	}
+
+	b, err := geo.CanonicalJSON()
+	if err != nil {
+		t.Errorf("Geo.CanonicalJSON returned error: %v", err)
+	}
+
+	if string(b) != `{"EU":{"RU":true},"OC":{"ALL":true}}` {
+		t.Errorf("Geo.CanonicalJSON = %s, expected %v", b, `{"EU":{"RU":true},"OC":{"ALL":true}}`)
+	}
+
+	if s := NewGeo().AllowWorld().String(); s != "ALL" {
+		t.Errorf("Geo.String = %v, expected %v", s, "ALL")
+	}
+}
@@ -0,0 +1,114 @@
+package filespot
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGeoBuilder(t *testing.T) {
+	geo := NewGeo().AllowContinent("na").AllowCountry("DE").AllowCountry("ru")
+
+	expected := Geo{
+		"NA": {"ALL": true},
+		"EU": {"DE": true, "RU": true},
+	}
+
+	if !reflect.DeepEqual(geo, expected) {
+		t.Errorf("Geo = %v, expected %v", geo, expected)
+	}
+
+	if err := geo.Validate(); err != nil {
+		t.Errorf("Geo.Validate returned error: %v", err)
+	}
+
+	// country of a granted continent is already allowed
+	geo.AllowCountry("US")
+	if !reflect.DeepEqual(geo["NA"], map[string]bool{"ALL": true}) {
+		t.Errorf("Geo NA = %v, expected %v", geo["NA"], map[string]bool{"ALL": true})
+	}
+}
+
+func TestGeoAllowWorld(t *testing.T) {
+	geo := NewGeo().AllowWorld()
+
+	if len(geo) != len(continents) || !geo.grantsWorld() {
+		t.Errorf("Geo = %v, expected all continents", geo)
+	}
+
+	if !permissiveGeo(geo) {
+		t.Error("permissiveGeo = false, expected true")
+	}
+}
+
+func TestGeoValidate(t *testing.T) {
+	tests := []struct {
+		geo    Geo
+		reason string
+	}{
+		{Geo{"EU": {"RU": true}}, ""},
+		{Geo{"AS": {"RU": true}}, ""},
+		{Geo{"EU": {"ALL": true}}, ""},
+		{Geo{"ALL": {"ALL": true}}, ""},
+		{Geo{"ALL": {"RU": true}}, "world accepts only ALL"},
+		{Geo{"eu": {"RU": true}}, "code must be capitalized"},
+		{Geo{"EU": {"ru": true}}, "code must be capitalized"},
+		{Geo{"XX": {"ALL": true}}, "unknown continent"},
+		{Geo{"EU": {"UK": true}}, "unknown country"},
+		{Geo{"EU": {"US": true}}, "country belongs to NA"},
+		{NewGeo().AllowCountry("ZZ"), "unknown country"},
+	}
+
+	for _, tt := range tests {
+		err := tt.geo.Validate()
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("Geo(%v).Validate returned error: %v", tt.geo, err)
+			}
+			continue
+		}
+
+		geoErr, ok := err.(*GeoError)
+		if !ok || geoErr.Reason != tt.reason {
+			t.Errorf("Geo(%v).Validate = %v, expected %v", tt.geo, err, tt.reason)
+		}
+	}
+}
+
+func TestGeoCountriesTable(t *testing.T) {
+	if len(countryContinents) != 249 {
+		t.Errorf("countries = %v, expected %v", len(countryContinents), 249)
+	}
+
+	for country, continent := range countryContinents {
+		if _, ok := continents[continent]; !ok {
+			t.Errorf("country %v continent = %v, expected ISO continent", country, continent)
+		}
+	}
+}
+
+func TestGeoValidateRequests(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request %v %v sent with invalid geo", r.Method, r.URL.Path)
+	})
+
+	geo := Geo{"EU": {"UK": true}}
+
+	_, _, err := client.Temp.Create(ctx, &LinkCreateRequest{ObjectID: "56787f0c044dfe226b000001", Geo: geo})
+	if _, ok := err.(*GeoError); !ok {
+		t.Errorf("Temp.Create error = %v, expected GeoError", err)
+	}
+
+	_, _, err = client.Players.Create(ctx, &PlayerCreateRequest{Name: "player", Geo: geo})
+	if _, ok := err.(*GeoError); !ok {
+		t.Errorf("Players.Create error = %v, expected GeoError", err)
+	}
+
+	_, err = client.Players.Update(ctx, "567d3643534b4474087c221e", &PlayerUpdateRequest{Name: "player", Geo: geo})
+	if _, ok := err.(*GeoError); !ok {
+		t.Errorf("Players.Update error = %v, expected GeoError", err)
+	}
+}
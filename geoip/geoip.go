@@ -0,0 +1,75 @@
+// Package geoip resolves viewer countries from MaxMind-format databases
+// (GeoLite2-Country, GeoIP2-Country, GeoLite2-City, DB-IP and compatible).
+// Reader implements filespot.CountryLookup:
+//
+//	db, err := geoip.Open("GeoLite2-Country.mmdb")
+//	access := &filespot.ViewerAccess{Lookup: db, ...}
+package geoip
+
+import (
+	"net"
+
+	"github.com/droff/filespot"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Reader looks up countries in a MaxMind-format database
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// record is the country part of database records
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Open opens database file
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// FromBytes reads database from memory
+func FromBytes(b []byte) (*Reader, error) {
+	db, err := maxminddb.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// Country implements filespot.CountryLookup.
+// Registered country is used when the database has no location country of ip.
+func (r *Reader) Country(ip net.IP) (string, error) {
+	var rec record
+
+	err := r.db.Lookup(ip, &rec)
+	if err != nil {
+		return "", err
+	}
+
+	if rec.Country.ISOCode != "" {
+		return rec.Country.ISOCode, nil
+	}
+
+	if rec.RegisteredCountry.ISOCode != "" {
+		return rec.RegisteredCountry.ISOCode, nil
+	}
+
+	return "", filespot.ErrUnknownCountry
+}
+
+// Close closes database
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
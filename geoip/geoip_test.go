@@ -0,0 +1,86 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/droff/filespot"
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+)
+
+// testDatabase returns GeoIP2-Country like database
+func testDatabase(t *testing.T) []byte {
+	writer, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: "GeoIP2-Country", RecordSize: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	networks := []struct {
+		cidr   string
+		record mmdbtype.Map
+	}{
+		{"188.111.110.0/24", mmdbtype.Map{
+			"country": mmdbtype.Map{"iso_code": mmdbtype.String("RU")},
+		}},
+		{"8.8.8.0/24", mmdbtype.Map{
+			"registered_country": mmdbtype.Map{"iso_code": mmdbtype.String("US")},
+		}},
+		{"81.2.69.0/24", mmdbtype.Map{
+			"continent": mmdbtype.Map{"code": mmdbtype.String("EU")},
+		}},
+	}
+
+	for _, n := range networks {
+		_, network, _ := net.ParseCIDR(n.cidr)
+		if err := writer.Insert(network, n.record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := writer.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestReaderCountry(t *testing.T) {
+	reader, err := FromBytes(testDatabase(t))
+	if err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	defer reader.Close()
+
+	tests := []struct {
+		ip      string
+		country string
+		err     error
+	}{
+		{"188.111.110.11", "RU", nil},
+		{"8.8.8.8", "US", nil},
+		{"81.2.69.142", "", filespot.ErrUnknownCountry},
+		{"127.0.0.1", "", filespot.ErrUnknownCountry},
+	}
+
+	for _, tt := range tests {
+		country, err := reader.Country(net.ParseIP(tt.ip))
+		if country != tt.country || err != tt.err {
+			t.Errorf("Reader.Country(%v) = %v, %v, expected %v, %v", tt.ip, country, err, tt.country, tt.err)
+		}
+	}
+}
+
+func TestReaderGeo(t *testing.T) {
+	reader, _ := FromBytes(testDatabase(t))
+	defer reader.Close()
+
+	var lookup filespot.CountryLookup = reader
+	country, _ := lookup.Country(net.ParseIP("188.111.110.11"))
+
+	if !(filespot.Geo{"EU": {"RU": true}}).Allows(country) {
+		t.Errorf("Geo.Allows(%v) = false, expected true", country)
+	}
+}
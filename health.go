@@ -0,0 +1,370 @@
+package filespot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProbeTimeout     = 5 * time.Second
+	defaultHealthHistory    = 20
+	defaultProbeConcurrency = 8
+	rtmpVersion             = 3
+	rtmpHandshakeSize       = 1536
+	playlistProbeLimit      = 64 << 10
+)
+
+// defaultProbePorts of schemes probed via TCP
+var defaultProbePorts = map[string]string{
+	"rtmp":  "1935",
+	"rtmps": "443",
+}
+
+// ErrStreamUnhealthy is returned by guarded StreamsService.Start when the stream source is down
+var ErrStreamUnhealthy = errors.New("filespot: stream source is unhealthy")
+
+// ProbeResult is a single availability check of stream source
+type ProbeResult struct {
+	Time    time.Time
+	Healthy bool
+	Latency time.Duration
+	Err     error
+}
+
+// StreamHealth probes sources of streams and keeps availability history.
+// Source is probed depending on URL scheme: HTTP(S) fetches HLS playlist,
+// RTMP(S) makes handshake and any other scheme connects to host and port via TCP.
+// StreamHealth is safe for concurrent use.
+type StreamHealth struct {
+	Streams StreamsService
+	// Timeout of a single probe, five seconds by default
+	Timeout time.Duration
+	// HistorySize is number of kept results per stream, 20 by default
+	HistorySize int
+	// Concurrency limits number of streams probed at once by ProbeAll, 8 by default
+	Concurrency int
+	// HTTPClient fetches playlists, http.DefaultClient by default
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	history map[string][]ProbeResult
+}
+
+// NewStreamHealth returns StreamHealth of streams
+func NewStreamHealth(streams StreamsService) *StreamHealth {
+	return &StreamHealth{
+		Streams: streams,
+	}
+}
+
+// Probe checks source of stream and records the result
+func (h *StreamHealth) Probe(ctx context.Context, stream *Stream) ProbeResult {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.probe(ctx, stream.URL)
+	result := ProbeResult{
+		Time:    start,
+		Healthy: err == nil,
+		Latency: time.Since(start),
+		Err:     err,
+	}
+
+	limit := h.HistorySize
+	if limit <= 0 {
+		limit = defaultHealthHistory
+	}
+
+	h.mu.Lock()
+	if h.history == nil {
+		h.history = make(map[string][]ProbeResult)
+	}
+	history := append(h.history[stream.ID], result)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	h.history[stream.ID] = history
+	h.mu.Unlock()
+
+	return result
+}
+
+// ProbeAll probes all streams concurrently, it stops starting probes when ctx is done
+func (h *StreamHealth) ProbeAll(ctx context.Context) error {
+	streams, _, err := h.Streams.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	concurrency := h.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultProbeConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range streams {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		// a slot may be freed by probe which was cancelled
+		if ctx.Err() != nil {
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(stream *Stream) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			h.Probe(ctx, stream)
+		}(&streams[i])
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// Run calls ProbeAll every interval until ctx is done
+func (h *StreamHealth) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.ProbeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// History returns probe results of stream from the oldest one
+func (h *StreamHealth) History(streamID string) []ProbeResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]ProbeResult(nil), h.history[streamID]...)
+}
+
+// Healthy reports whether the last probe of stream succeeded, known is false when it wasn't probed
+func (h *StreamHealth) Healthy(streamID string) (healthy, known bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := h.history[streamID]
+	if len(history) == 0 {
+		return false, false
+	}
+
+	return history[len(history)-1].Healthy, true
+}
+
+// Availability returns share of successful probes of stream in history
+func (h *StreamHealth) Availability(streamID string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := h.history[streamID]
+	if len(history) == 0 {
+		return 0
+	}
+
+	healthy := 0
+	for _, result := range history {
+		if result.Healthy {
+			healthy++
+		}
+	}
+
+	return float64(healthy) / float64(len(history))
+}
+
+// Guard returns StreamsService refusing to Start streams with unhealthy source.
+// Stream which wasn't probed yet is probed before start.
+func (h *StreamHealth) Guard(streams StreamsService) StreamsService {
+	return &guardedStreams{StreamsService: streams, health: h}
+}
+
+// guardedStreams is StreamsService checking health before Start
+type guardedStreams struct {
+	StreamsService
+	health *StreamHealth
+}
+
+// Start Stream when its source is healthy
+func (g *guardedStreams) Start(ctx context.Context, id string, streamStartRequest *StreamStartRequest) (*http.Response, error) {
+	healthy, known := g.health.Healthy(id)
+	if !known {
+		stream, resp, err := g.StreamsService.Get(ctx, id)
+		if err != nil {
+			return resp, err
+		}
+
+		healthy = g.health.Probe(ctx, stream).Healthy
+	}
+
+	if !healthy {
+		return nil, ErrStreamUnhealthy
+	}
+
+	return g.StreamsService.Start(ctx, id, streamStartRequest)
+}
+
+// probe checks source URL
+func (h *StreamHealth) probe(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return h.probeHTTP(ctx, u)
+	case "rtmp", "rtmps":
+		return probeRTMP(ctx, u)
+	default:
+		conn, err := dialProbe(ctx, u)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// probeHTTP fetches URL and checks it's HLS playlist, HTTP sources of other formats are unhealthy
+func (h *StreamHealth) probeHTTP(ctx context.Context, u *url.URL) error {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	client := h.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("filespot: probe %v returned %v", u.Redacted(), resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, playlistProbeLimit))
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("#EXTM3U")) {
+		return fmt.Errorf("filespot: probe %v returned no HLS playlist", u.Redacted())
+	}
+
+	return nil
+}
+
+// probeRTMP makes RTMP handshake.
+// See https://rtmp.veriskope.com/docs/spec/#52handshake
+func probeRTMP(ctx context.Context, u *url.URL) error {
+	conn, err := dialProbe(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c1 := make([]byte, 1+rtmpHandshakeSize)
+	c1[0] = rtmpVersion
+	// time and zero fields are followed by random bytes
+	rand.Read(c1[9:])
+
+	_, err = conn.Write(c1)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	s1 := make([]byte, 1+rtmpHandshakeSize)
+	_, err = io.ReadFull(r, s1)
+	if err != nil {
+		return fmt.Errorf("filespot: rtmp handshake: %v", err)
+	}
+
+	if s1[0] != rtmpVersion {
+		return fmt.Errorf("filespot: rtmp handshake: unsupported version %v", s1[0])
+	}
+
+	_, err = conn.Write(s1[1:])
+	if err != nil {
+		return err
+	}
+
+	// S2 isn't compared with C1 as servers using digest handshake don't echo it
+	s2 := make([]byte, rtmpHandshakeSize)
+	_, err = io.ReadFull(r, s2)
+	if err != nil {
+		return fmt.Errorf("filespot: rtmp handshake: %v", err)
+	}
+
+	return nil
+}
+
+// dialProbe connects to host of URL, TLS is used for rtmps
+func dialProbe(ctx context.Context, u *url.URL) (net.Conn, error) {
+	address, err := probeAddress(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "rtmps" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: u.Hostname()}}
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+// probeAddress returns host and port of URL, port defaults to the one of scheme
+func probeAddress(u *url.URL) (string, error) {
+	port := u.Port()
+	if port == "" {
+		port = defaultProbePorts[u.Scheme]
+	}
+	if port == "" {
+		return "", fmt.Errorf("filespot: probe %v: port is missing", u.Redacted())
+	}
+
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
@@ -0,0 +1,292 @@
+package filespot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rtmpServer accepts RTMP handshakes until closed
+func rtmpServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen returned error: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				c1 := make([]byte, 1+rtmpHandshakeSize)
+				if _, err := io.ReadFull(conn, c1); err != nil {
+					return
+				}
+
+				s1 := make([]byte, 1+rtmpHandshakeSize)
+				s1[0] = rtmpVersion
+				conn.Write(s1)
+				conn.Write(c1[1:])
+
+				io.ReadFull(conn, make([]byte, rtmpHandshakeSize))
+			}(conn)
+		}
+	}()
+
+	return l
+}
+
+// closedAddr returns address nobody listens on
+func closedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen returned error: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestStreamHealthProbe(t *testing.T) {
+	hls := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live.m3u8":
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:6\n")
+		case "/page.html":
+			fmt.Fprint(w, "<html></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer hls.Close()
+
+	rtmp := rtmpServer(t)
+	defer rtmp.Close()
+
+	closed := closedAddr(t)
+
+	tests := []struct {
+		url     string
+		healthy bool
+	}{
+		{hls.URL + "/live.m3u8", true},
+		{hls.URL + "/page.html", false},
+		{hls.URL + "/gone.m3u8", false},
+		{"rtmp://" + rtmp.Addr().String() + "/live/key", true},
+		{"rtmp://" + closed + "/live/key", false},
+		{"srt://" + rtmp.Addr().String(), true},
+		{"srt://" + closed, false},
+		{"udp://127.0.0.1", false},
+	}
+
+	health := NewStreamHealth(nil)
+	health.Timeout = time.Second
+
+	for _, tt := range tests {
+		result := health.Probe(ctx, &Stream{ID: "56cec7e2fa63afd0f843567d", URL: tt.url})
+		if result.Healthy != tt.healthy {
+			t.Errorf("StreamHealth.Probe(%v) = %v (%v), expected %v", tt.url, result.Healthy, result.Err, tt.healthy)
+		}
+	}
+
+	history := health.History("56cec7e2fa63afd0f843567d")
+	if len(history) != len(tests) {
+		t.Errorf("StreamHealth.History = %v results, expected %v", len(history), len(tests))
+	}
+
+	if availability := health.Availability("56cec7e2fa63afd0f843567d"); availability != 3.0/8.0 {
+		t.Errorf("StreamHealth.Availability = %v, expected %v", availability, 3.0/8.0)
+	}
+}
+
+func TestStreamHealthHistoryLimit(t *testing.T) {
+	closed := closedAddr(t)
+
+	health := NewStreamHealth(nil)
+	health.HistorySize = 3
+
+	for i := 0; i < 5; i++ {
+		health.Probe(ctx, &Stream{ID: "56cec7e2fa63afd0f843567d", URL: "tcp://" + closed})
+	}
+
+	if history := health.History("56cec7e2fa63afd0f843567d"); len(history) != 3 {
+		t.Errorf("StreamHealth.History = %v results, expected %v", len(history), 3)
+	}
+}
+
+func TestStreamHealthGuard(t *testing.T) {
+	setup()
+	defer teardown()
+
+	rtmp := rtmpServer(t)
+	defer rtmp.Close()
+
+	streams := map[string]string{
+		"56cec7e2fa63afd0f843567d": "rtmp://" + rtmp.Addr().String() + "/live/key",
+		"56cec7e2fa63afd0f843567e": "rtmp://" + closedAddr(t) + "/live/key",
+	}
+
+	mux.HandleFunc("/1/streams", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "streams": [`)
+		first := true
+		for id, u := range streams {
+			if !first {
+				fmt.Fprint(w, ",")
+			}
+			first = false
+			fmt.Fprintf(w, `{"id": %q, "url": %q}`, id, u)
+		}
+		fmt.Fprint(w, `]}`)
+	})
+
+	for id, u := range streams {
+		id, u := id, u
+		mux.HandleFunc("/1/streams/"+id, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"code": 200, "status": "success", "stream": {"id": %q, "url": %q}}`, id, u)
+		})
+		mux.HandleFunc("/1/streams/rec/instant/start/"+id, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"code": 200, "status": "success"}`)
+		})
+	}
+
+	health := NewStreamHealth(client.Streams)
+	guarded := health.Guard(client.Streams)
+
+	// not probed yet, probed on demand
+	_, err := guarded.Start(ctx, "56cec7e2fa63afd0f843567d", nil)
+	if err != nil {
+		t.Errorf("Streams.Start returned error: %v", err)
+	}
+
+	err = health.ProbeAll(context.Background())
+	if err != nil {
+		t.Fatalf("StreamHealth.ProbeAll returned error: %v", err)
+	}
+
+	if healthy, known := health.Healthy("56cec7e2fa63afd0f843567e"); healthy || !known {
+		t.Errorf("StreamHealth.Healthy = %v, %v, expected unhealthy", healthy, known)
+	}
+
+	_, err = guarded.Start(ctx, "56cec7e2fa63afd0f843567e", nil)
+	if err != ErrStreamUnhealthy {
+		t.Errorf("Streams.Start returned error: %v, expected %v", err, ErrStreamUnhealthy)
+	}
+}
+
+func TestStreamHealthProbeAddress(t *testing.T) {
+	tests := map[string]string{
+		"rtmp://example.com/live/key":       "example.com:1935",
+		"rtmps://example.com/live/key":      "example.com:443",
+		"rtmps://example.com:8443/live/key": "example.com:8443",
+		"srt://example.com:9000":            "example.com:9000",
+		"srt://example.com":                 "",
+	}
+
+	for rawURL, expected := range tests {
+		u, _ := url.Parse(rawURL)
+		address, err := probeAddress(u)
+		if address != expected || (err == nil) != (expected != "") {
+			t.Errorf("probeAddress(%v) = %v, %v, expected %v", rawURL, address, err, expected)
+		}
+	}
+}
+
+func TestStreamHealthProbeAllConcurrency(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "#EXTM3U\n")
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer source.Close()
+
+	mux.HandleFunc("/1/streams", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "streams": [`)
+		for i := 0; i < 6; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id": "56cec7e2fa63afd0f843567%d", "url": "%v/live.m3u8"}`, i, source.URL)
+		}
+		fmt.Fprint(w, `]}`)
+	})
+
+	health := NewStreamHealth(client.Streams)
+	health.Concurrency = 2
+
+	err := health.ProbeAll(ctx)
+	if err != nil {
+		t.Fatalf("StreamHealth.ProbeAll returned error: %v", err)
+	}
+
+	if peak > 2 {
+		t.Errorf("StreamHealth.ProbeAll probed %v streams at once, expected at most %v", peak, 2)
+	}
+
+	if healthy, known := health.Healthy("56cec7e2fa63afd0f8435675"); !healthy || !known {
+		t.Errorf("StreamHealth.Healthy = %v, %v, expected healthy", healthy, known)
+	}
+}
+
+func TestStreamHealthProbeAllCancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	probing := make(chan struct{}, 6)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probing <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer source.Close()
+
+	mux.HandleFunc("/1/streams", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"code": 200, "status": "success", "streams": [
+            {"id": "56cec7e2fa63afd0f8435670", "url": "%v/live.m3u8"},
+            {"id": "56cec7e2fa63afd0f8435671", "url": "%v/live.m3u8"}
+        ]}`, source.URL, source.URL)
+	})
+
+	health := NewStreamHealth(client.Streams)
+	health.Concurrency = 1
+
+	probeCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-probing
+		cancel()
+	}()
+
+	err := health.ProbeAll(probeCtx)
+	if err != context.Canceled {
+		t.Errorf("StreamHealth.ProbeAll returned error: %v, expected %v", err, context.Canceled)
+	}
+
+	if len(probing) != 0 {
+		t.Errorf("StreamHealth.ProbeAll probed %v streams after cancel, expected none", len(probing))
+	}
+}
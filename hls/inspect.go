@@ -0,0 +1,360 @@
+package hls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/droff/filespot"
+)
+
+const (
+	defaultConcurrency = 4
+	playlistLimit      = 4 << 20
+	// segmentTolerance is allowed excess of segment over expected duration,
+	// segments are cut on key frames so they aren't exact
+	segmentTolerance = 1.5
+)
+
+// ErrNoPlaylist is returned by Inspector.InspectObject when object has no HLS playlist
+var ErrNoPlaylist = errors.New("hls: object has no HLS playlist")
+
+// Inspector fetches playlists and validates renditions
+type Inspector struct {
+	// SegmentDuration is the expected duration of segments in seconds,
+	// e.g. TranscoderHLSRequest.SegmentDuration, it isn't checked when zero
+	SegmentDuration int
+	// CheckSegments requests every segment to find missing ones
+	CheckSegments bool
+	// Concurrency limits number of segments requested at once, 4 by default
+	Concurrency int
+	// HTTPClient fetches playlists and segments, http.DefaultClient by default
+	HTTPClient *http.Client
+}
+
+// Report is result of inspection
+type Report struct {
+	URL string
+	// Master is nil when URL is a media playlist
+	Master     *MasterPlaylist
+	Renditions []Rendition
+}
+
+// Rendition is summary of media playlist
+type Rendition struct {
+	URL string
+	// Variant of master playlist, zero when URL is a media playlist
+	Variant  Variant
+	Playlist *MediaPlaylist
+	Duration time.Duration
+	// LongestSegment is the maximal segment duration
+	LongestSegment  time.Duration
+	Discontinuities int
+	// Missing are URLs of segments which are gaps or weren't fetched
+	Missing []string
+	// Problems found by validation
+	Problems []string
+	// Err is set when the playlist wasn't fetched or parsed
+	Err error
+}
+
+// NewInspector returns Inspector expecting segments of segmentDuration seconds
+func NewInspector(segmentDuration int) *Inspector {
+	return &Inspector{
+		SegmentDuration: segmentDuration,
+	}
+}
+
+// InspectObject inspects HLS playlist of transcoded object
+func (i *Inspector) InspectObject(ctx context.Context, object *filespot.Object) (*Report, error) {
+	if object.VODHLS == "" {
+		return nil, ErrNoPlaylist
+	}
+
+	u := object.VODHLS
+	if !strings.Contains(u, "://") {
+		u = "https://" + strings.TrimPrefix(u, "//")
+	}
+
+	return i.Inspect(ctx, u)
+}
+
+// Inspect fetches playlist of URL, master playlist is inspected with every variant
+func (i *Inspector) Inspect(ctx context.Context, rawURL string) (*Report, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := i.fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{URL: rawURL}
+
+	if !IsMaster(body) {
+		playlist, err := ParseMedia(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		rendition := Rendition{URL: rawURL}
+		i.validate(ctx, u, playlist, &rendition)
+		report.Renditions = []Rendition{rendition}
+
+		return report, nil
+	}
+
+	report.Master, err = ParseMaster(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Master.Variants) == 0 {
+		return nil, errors.New("hls: master playlist has no variants")
+	}
+
+	report.Renditions = make([]Rendition, len(report.Master.Variants))
+	for n, variant := range report.Master.Variants {
+		rendition := &report.Renditions[n]
+		rendition.Variant = variant
+
+		ref, err := u.Parse(variant.URI)
+		if err != nil {
+			rendition.Err = err
+			continue
+		}
+		rendition.URL = ref.String()
+
+		body, err := i.fetch(ctx, ref)
+		if err != nil {
+			rendition.Err = err
+			continue
+		}
+
+		playlist, err := ParseMedia(bytes.NewReader(body))
+		if err != nil {
+			rendition.Err = err
+			continue
+		}
+
+		i.validate(ctx, ref, playlist, rendition)
+	}
+
+	return report, ctx.Err()
+}
+
+// OK reports whether every rendition has no problems
+func (r *Report) OK() bool {
+	for n := range r.Renditions {
+		if !r.Renditions[n].OK() {
+			return false
+		}
+	}
+
+	return len(r.Renditions) > 0
+}
+
+// String returns summary of renditions
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%v\n", r.URL)
+	for n := range r.Renditions {
+		sb.WriteString(r.Renditions[n].String())
+	}
+
+	return sb.String()
+}
+
+// OK reports whether rendition has no problems
+func (r *Rendition) OK() bool {
+	return r.Err == nil && len(r.Problems) == 0
+}
+
+// String returns summary of rendition
+func (r *Rendition) String() string {
+	var sb strings.Builder
+
+	name := r.URL
+	if r.Variant.Height > 0 {
+		name = fmt.Sprintf("%dx%d %d bit/s", r.Variant.Width, r.Variant.Height, r.Variant.Bandwidth)
+	}
+
+	if r.Err != nil {
+		fmt.Fprintf(&sb, "  %v: %v\n", name, r.Err)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "  %v: %d segments, %v, longest %v, %d discontinuities, %d missing\n",
+		name, len(r.Playlist.Segments), r.Duration, r.LongestSegment, r.Discontinuities, len(r.Missing))
+	for _, problem := range r.Problems {
+		fmt.Fprintf(&sb, "    %v\n", problem)
+	}
+
+	return sb.String()
+}
+
+// validate summarizes playlist of URL u into rendition
+func (i *Inspector) validate(ctx context.Context, u *url.URL, playlist *MediaPlaylist, r *Rendition) {
+	r.Playlist = playlist
+	r.Duration = playlist.Duration()
+
+	problem := func(format string, a ...interface{}) {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, a...))
+	}
+
+	if len(playlist.Segments) == 0 {
+		problem("playlist has no segments")
+	}
+
+	if !playlist.EndList {
+		problem("playlist has no EXT-X-ENDLIST")
+	}
+
+	expected := time.Duration(i.SegmentDuration) * time.Second
+	if i.SegmentDuration > 0 && playlist.TargetDuration > 0 &&
+		float64(playlist.TargetDuration) > float64(i.SegmentDuration)*segmentTolerance {
+		problem("EXT-X-TARGETDURATION %d exceeds segment duration %d", playlist.TargetDuration, i.SegmentDuration)
+	}
+
+	urls := make([]string, 0, len(playlist.Segments))
+	for n, segment := range playlist.Segments {
+		if segment.Duration > r.LongestSegment {
+			r.LongestSegment = segment.Duration
+		}
+
+		if segment.Discontinuity {
+			r.Discontinuities++
+			problem("discontinuity before segment %d", segment.Sequence)
+		}
+
+		// rounded EXTINF must not exceed target duration, see RFC 8216 section 4.3.3.1
+		if playlist.TargetDuration > 0 && int(math.Round(segment.Duration.Seconds())) > playlist.TargetDuration {
+			problem("segment %d of %v exceeds EXT-X-TARGETDURATION %d", segment.Sequence, segment.Duration, playlist.TargetDuration)
+		}
+
+		// the last segment is the rest of video
+		if expected > 0 && n < len(playlist.Segments)-1 &&
+			float64(segment.Duration) > float64(expected)*segmentTolerance {
+			problem("segment %d of %v is longer than %v", segment.Sequence, segment.Duration, expected)
+		}
+
+		ref, err := u.Parse(segment.URI)
+		if err != nil {
+			problem("segment %d has bad URI %q", segment.Sequence, segment.URI)
+			continue
+		}
+
+		if segment.Gap {
+			r.Missing = append(r.Missing, ref.String())
+			continue
+		}
+
+		urls = append(urls, ref.String())
+	}
+
+	if i.CheckSegments {
+		r.Missing = append(r.Missing, i.missing(ctx, urls)...)
+	}
+
+	for _, missing := range r.Missing {
+		problem("segment %v is missing", missing)
+	}
+}
+
+// missing requests segments and returns URLs of unavailable ones in order
+func (i *Inspector) missing(ctx context.Context, urls []string) []string {
+	concurrency := i.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	failed := make([]bool, len(urls))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for n, u := range urls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(n int, u string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			failed[n] = i.check(ctx, u) != nil
+		}(n, u)
+	}
+	wg.Wait()
+
+	var missing []string
+	for n, u := range urls {
+		if failed[n] {
+			missing = append(missing, u)
+		}
+	}
+
+	return missing
+}
+
+// check requests the first byte of segment
+func (i *Inspector) check(ctx context.Context, rawURL string) error {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := i.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("hls: %v returned %v", rawURL, resp.Status)
+	}
+
+	return nil
+}
+
+// fetch returns playlist of URL
+func (i *Inspector) fetch(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := i.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("hls: %v returned %v", u.Redacted(), resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, playlistLimit))
+}
+
+// client returns HTTP client
+func (i *Inspector) client() *http.Client {
+	if i.HTTPClient != nil {
+		return i.HTTPClient
+	}
+
+	return http.DefaultClient
+}
@@ -0,0 +1,133 @@
+package hls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/droff/filespot"
+)
+
+// testServer serves HLS presentation with a healthy 720p rendition and a broken 480p one
+func testServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/vod/master.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, masterPlaylist)
+	})
+
+	mux.HandleFunc("/vod/720/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\nseg0.ts\n#EXTINF:6.0,\nseg1.ts\n#EXTINF:1.2,\nseg2.ts\n#EXT-X-ENDLIST\n")
+	})
+
+	mux.HandleFunc("/vod/480/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-TARGETDURATION:10\n#EXTINF:10.0,\nseg0.ts\n#EXT-X-DISCONTINUITY\n#EXTINF:6.0,\nseg1.ts\n#EXTINF:6.0,\nlost.ts\n")
+	})
+
+	mux.HandleFunc("/vod/720/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=0-0" {
+			t.Errorf("segment Range = %q, expected %q", r.Header.Get("Range"), "bytes=0-0")
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte{0x47})
+	})
+
+	mux.HandleFunc("/vod/480/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/lost.ts") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte{0x47})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestInspector_Inspect(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	inspector := NewInspector(6)
+	inspector.CheckSegments = true
+
+	report, err := inspector.Inspect(context.Background(), server.URL+"/vod/master.m3u8")
+	if err != nil {
+		t.Fatalf("Inspector.Inspect returned error: %v", err)
+	}
+
+	if report.Master == nil || len(report.Renditions) != 2 {
+		t.Fatalf("Inspector.Inspect = %+v, expected master with 2 renditions", report)
+	}
+
+	hd := report.Renditions[0]
+	if !hd.OK() || hd.URL != server.URL+"/vod/720/index.m3u8" || len(hd.Playlist.Segments) != 3 || hd.Duration.Seconds() != 13.2 {
+		t.Errorf("720p rendition = %+v, expected 3 segments of 13.2s without problems", hd)
+	}
+
+	sd := report.Renditions[1]
+	expected := []string{
+		"playlist has no EXT-X-ENDLIST",
+		"EXT-X-TARGETDURATION 10 exceeds segment duration 6",
+		"segment 0 of 10s is longer than 6s",
+		"discontinuity before segment 1",
+		"segment " + server.URL + "/vod/480/lost.ts is missing",
+	}
+	if strings.Join(sd.Problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("480p problems = %q, expected %q", sd.Problems, expected)
+	}
+
+	if sd.Discontinuities != 1 || sd.LongestSegment.Seconds() != 10 {
+		t.Errorf("480p rendition = %+v, expected 1 discontinuity and longest segment 10s", sd)
+	}
+
+	if report.OK() {
+		t.Errorf("Report.OK = true, expected false")
+	}
+
+	summary := report.String()
+	for _, line := range []string{"1280x720 2800000 bit/s: 3 segments, 13.2s", "854x480 1400000 bit/s: 3 segments, 22s, longest 10s, 1 discontinuities, 1 missing"} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Report.String = %v, expected to contain %q", summary, line)
+		}
+	}
+}
+
+func TestInspector_InspectMedia(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	report, err := NewInspector(0).Inspect(context.Background(), server.URL+"/vod/720/index.m3u8")
+	if err != nil {
+		t.Fatalf("Inspector.Inspect returned error: %v", err)
+	}
+
+	if report.Master != nil || len(report.Renditions) != 1 || !report.OK() {
+		t.Errorf("Inspector.Inspect = %+v, expected a healthy media playlist", report)
+	}
+}
+
+func TestInspector_InspectObject(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	inspector := NewInspector(6)
+
+	_, err := inspector.InspectObject(context.Background(), &filespot.Object{})
+	if err != ErrNoPlaylist {
+		t.Errorf("Inspector.InspectObject error = %v, expected %v", err, ErrNoPlaylist)
+	}
+
+	object := &filespot.Object{VODHLS: server.URL + "/vod/master.m3u8"}
+	report, err := inspector.InspectObject(context.Background(), object)
+	if err != nil || len(report.Renditions) != 2 {
+		t.Errorf("Inspector.InspectObject = %+v, %v, expected 2 renditions", report, err)
+	}
+
+	_, err = inspector.Inspect(context.Background(), server.URL+"/vod/missing.m3u8")
+	if err == nil {
+		t.Errorf("Inspector.Inspect expected error of missing playlist")
+	}
+}
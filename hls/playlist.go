@@ -0,0 +1,332 @@
+// Package hls fetches, parses and validates HLS playlists of transcoded objects:
+//
+//	inspector := hls.NewInspector(transcoderHLSRequest.SegmentDuration)
+//	report, err := inspector.InspectObject(ctx, object)
+//	fmt.Print(report)
+//
+// See https://tools.ietf.org/html/rfc8216
+package hls
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotPlaylist is returned when the document doesn't start with #EXTM3U
+var ErrNotPlaylist = errors.New("hls: not a playlist")
+
+// segmentTags apply to the next media segment
+var segmentTags = map[string]bool{
+	"EXT-X-BYTERANGE":         true,
+	"EXT-X-KEY":               true,
+	"EXT-X-MAP":               true,
+	"EXT-X-PROGRAM-DATE-TIME": true,
+	"EXT-X-DATERANGE":         true,
+	"EXT-X-BITRATE":           true,
+}
+
+// Tag is a playlist tag without dedicated field, Name has no leading "#"
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// MasterPlaylist lists variants of presentation
+type MasterPlaylist struct {
+	Version  int
+	Variants []Variant
+	// Tags are the other tags, e.g. EXT-X-MEDIA
+	Tags []Tag
+}
+
+// Variant is EXT-X-STREAM-INF of master playlist
+type Variant struct {
+	URI              string
+	Bandwidth        int
+	AverageBandwidth int
+	Width            int
+	Height           int
+	Codecs           []string
+	FrameRate        float64
+	// Attributes are all attributes of the tag
+	Attributes map[string]string
+}
+
+// MediaPlaylist lists segments of rendition
+type MediaPlaylist struct {
+	Version        int
+	TargetDuration int
+	MediaSequence  int
+	PlaylistType   string
+	EndList        bool
+	Segments       []Segment
+	// Tags are the other playlist tags
+	Tags []Tag
+}
+
+// Segment is a media segment of MediaPlaylist
+type Segment struct {
+	URI      string
+	Duration time.Duration
+	Title    string
+	// Sequence number of segment
+	Sequence      int
+	Discontinuity bool
+	// Gap marks segment as unavailable
+	Gap bool
+	// Tags applied to segment, e.g. EXT-X-KEY
+	Tags []Tag
+}
+
+// IsMaster reports whether playlist is a master one
+func IsMaster(playlist []byte) bool {
+	return strings.Contains(string(playlist), "#EXT-X-STREAM-INF")
+}
+
+// ParseMaster parses master playlist
+func ParseMaster(r io.Reader) (*MasterPlaylist, error) {
+	p := new(MasterPlaylist)
+
+	var pending *Variant
+	err := scan(r, func(name, value string) error {
+		if pending != nil && name != "" {
+			return fmt.Errorf("hls: EXT-X-STREAM-INF isn't followed by URI")
+		}
+
+		switch name {
+		case "":
+			if pending == nil {
+				return fmt.Errorf("hls: URI %q without EXT-X-STREAM-INF", value)
+			}
+			pending.URI = value
+			p.Variants = append(p.Variants, *pending)
+			pending = nil
+		case "EXT-X-VERSION":
+			return parseInt(name, value, &p.Version)
+		case "EXT-X-STREAM-INF":
+			variant, err := parseVariant(value)
+			if err != nil {
+				return err
+			}
+			pending = variant
+		default:
+			p.Tags = append(p.Tags, Tag{Name: name, Value: value})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil {
+		return nil, fmt.Errorf("hls: EXT-X-STREAM-INF isn't followed by URI")
+	}
+
+	return p, nil
+}
+
+// ParseMedia parses media playlist
+func ParseMedia(r io.Reader) (*MediaPlaylist, error) {
+	p := new(MediaPlaylist)
+
+	var (
+		segment Segment
+		inf     bool
+	)
+	err := scan(r, func(name, value string) error {
+		switch {
+		case name == "":
+			if !inf {
+				return fmt.Errorf("hls: URI %q without EXTINF", value)
+			}
+			segment.URI = value
+			segment.Sequence = p.MediaSequence + len(p.Segments)
+			p.Segments = append(p.Segments, segment)
+			segment, inf = Segment{}, false
+		case name == "EXTINF":
+			duration, title, _ := strings.Cut(value, ",")
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(duration), 64)
+			if err != nil || seconds < 0 {
+				return fmt.Errorf("hls: bad EXTINF %q", value)
+			}
+			segment.Duration = time.Duration(seconds * float64(time.Second))
+			segment.Title = title
+			inf = true
+		case name == "EXT-X-DISCONTINUITY":
+			segment.Discontinuity = true
+		case name == "EXT-X-GAP":
+			segment.Gap = true
+		case segmentTags[name]:
+			segment.Tags = append(segment.Tags, Tag{Name: name, Value: value})
+		case name == "EXT-X-VERSION":
+			return parseInt(name, value, &p.Version)
+		case name == "EXT-X-TARGETDURATION":
+			return parseInt(name, value, &p.TargetDuration)
+		case name == "EXT-X-MEDIA-SEQUENCE":
+			return parseInt(name, value, &p.MediaSequence)
+		case name == "EXT-X-PLAYLIST-TYPE":
+			p.PlaylistType = value
+		case name == "EXT-X-ENDLIST":
+			p.EndList = true
+		default:
+			p.Tags = append(p.Tags, Tag{Name: name, Value: value})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Duration returns total duration of segments
+func (p *MediaPlaylist) Duration() time.Duration {
+	var d time.Duration
+	for _, segment := range p.Segments {
+		d += segment.Duration
+	}
+
+	return d
+}
+
+// scan calls fn with name and value of every tag, and with empty name and URI of every URI line.
+// Comments and blank lines are skipped.
+func scan(r io.Reader, fn func(name, value string) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64<<10), 1<<20)
+
+	first := true
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if first {
+			if strings.TrimPrefix(line, "\ufeff") != "#EXTM3U" {
+				return ErrNotPlaylist
+			}
+			first = false
+			continue
+		}
+
+		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#EXT")) {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			err := fn("", line)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, value, _ := strings.Cut(line[1:], ":")
+		err := fn(name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	if first {
+		return ErrNotPlaylist
+	}
+
+	return nil
+}
+
+// parseVariant parses attributes of EXT-X-STREAM-INF
+func parseVariant(value string) (*Variant, error) {
+	attrs, err := parseAttributes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Variant{Attributes: attrs}
+
+	v.Bandwidth, err = strconv.Atoi(attrs["BANDWIDTH"])
+	if err != nil {
+		return nil, fmt.Errorf("hls: bad BANDWIDTH %q", attrs["BANDWIDTH"])
+	}
+
+	if s, ok := attrs["AVERAGE-BANDWIDTH"]; ok {
+		v.AverageBandwidth, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("hls: bad AVERAGE-BANDWIDTH %q", s)
+		}
+	}
+
+	if s, ok := attrs["RESOLUTION"]; ok {
+		width, height, _ := strings.Cut(strings.ToLower(s), "x")
+		v.Width, err = strconv.Atoi(width)
+		if err == nil {
+			v.Height, err = strconv.Atoi(height)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hls: bad RESOLUTION %q", s)
+		}
+	}
+
+	if s, ok := attrs["CODECS"]; ok {
+		for _, codec := range strings.Split(s, ",") {
+			v.Codecs = append(v.Codecs, strings.TrimSpace(codec))
+		}
+	}
+
+	if s, ok := attrs["FRAME-RATE"]; ok {
+		v.FrameRate, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("hls: bad FRAME-RATE %q", s)
+		}
+	}
+
+	return v, nil
+}
+
+// parseAttributes parses attribute list like `BANDWIDTH=1280000,CODECS="avc1.4d401f,mp4a.40.2"`,
+// quotes of values are removed
+func parseAttributes(s string) (map[string]string, error) {
+	attrs := make(map[string]string)
+
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("hls: bad attribute list %q", s)
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("hls: unterminated attribute %v", name)
+			}
+			value, s = rest[1:end+1], strings.TrimPrefix(rest[end+2:], ",")
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+		}
+
+		attrs[strings.TrimSpace(name)] = value
+	}
+
+	return attrs, nil
+}
+
+// parseInt parses integer value of tag into v
+func parseInt(name, value string, v *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("hls: bad %v %q", name, value)
+	}
+
+	*v = n
+	return nil
+}
@@ -0,0 +1,139 @@
+package hls
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const masterPlaylist = `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-STREAM-INF:BANDWIDTH=2800000,AVERAGE-BANDWIDTH=2500000,RESOLUTION=1280x720,CODECS="avc1.4d401f,mp4a.40.2",FRAME-RATE=25.000
+720/index.m3u8
+# comment
+
+#EXT-X-STREAM-INF:BANDWIDTH=1400000,RESOLUTION=854x480
+480/index.m3u8
+`
+
+const mediaPlaylist = `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:10
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-ALLOW-CACHE:YES
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:6.000,
+seg10.ts
+#EXT-X-DISCONTINUITY
+#EXTINF:5.960,intro
+seg11.ts
+#EXT-X-GAP
+#EXTINF:2.5,
+seg12.ts
+#EXT-X-ENDLIST
+`
+
+func TestParseMaster(t *testing.T) {
+	p, err := ParseMaster(strings.NewReader(masterPlaylist))
+	if err != nil {
+		t.Fatalf("ParseMaster returned error: %v", err)
+	}
+
+	if p.Version != 3 || len(p.Variants) != 2 {
+		t.Fatalf("ParseMaster = %+v, expected version 3 with 2 variants", p)
+	}
+
+	expected := Variant{
+		URI:              "720/index.m3u8",
+		Bandwidth:        2800000,
+		AverageBandwidth: 2500000,
+		Width:            1280,
+		Height:           720,
+		Codecs:           []string{"avc1.4d401f", "mp4a.40.2"},
+		FrameRate:        25,
+		Attributes: map[string]string{
+			"BANDWIDTH":         "2800000",
+			"AVERAGE-BANDWIDTH": "2500000",
+			"RESOLUTION":        "1280x720",
+			"CODECS":            "avc1.4d401f,mp4a.40.2",
+			"FRAME-RATE":        "25.000",
+		},
+	}
+	if !reflect.DeepEqual(p.Variants[0], expected) {
+		t.Errorf("ParseMaster variant = %+v, expected %+v", p.Variants[0], expected)
+	}
+
+	if p.Variants[1].URI != "480/index.m3u8" || p.Variants[1].Height != 480 {
+		t.Errorf("ParseMaster variant = %+v, expected 480/index.m3u8 of height 480", p.Variants[1])
+	}
+
+	if !reflect.DeepEqual(p.Tags, []Tag{{Name: "EXT-X-INDEPENDENT-SEGMENTS"}}) {
+		t.Errorf("ParseMaster tags = %+v, expected EXT-X-INDEPENDENT-SEGMENTS", p.Tags)
+	}
+}
+
+func TestParseMedia(t *testing.T) {
+	p, err := ParseMedia(strings.NewReader(mediaPlaylist))
+	if err != nil {
+		t.Fatalf("ParseMedia returned error: %v", err)
+	}
+
+	if p.TargetDuration != 6 || p.MediaSequence != 10 || p.PlaylistType != "VOD" || !p.EndList {
+		t.Errorf("ParseMedia = %+v, expected VOD playlist with target 6 from sequence 10", p)
+	}
+
+	expected := []Segment{
+		{URI: "seg10.ts", Duration: 6 * time.Second, Sequence: 10, Tags: []Tag{{Name: "EXT-X-KEY", Value: "METHOD=NONE"}}},
+		{URI: "seg11.ts", Duration: 5960 * time.Millisecond, Title: "intro", Sequence: 11, Discontinuity: true},
+		{URI: "seg12.ts", Duration: 2500 * time.Millisecond, Sequence: 12, Gap: true},
+	}
+	if !reflect.DeepEqual(p.Segments, expected) {
+		t.Errorf("ParseMedia segments = %+v, expected %+v", p.Segments, expected)
+	}
+
+	if d := p.Duration(); d != 14460*time.Millisecond {
+		t.Errorf("MediaPlaylist.Duration = %v, expected %v", d, 14460*time.Millisecond)
+	}
+
+	if !reflect.DeepEqual(p.Tags, []Tag{{Name: "EXT-X-ALLOW-CACHE", Value: "YES"}}) {
+		t.Errorf("ParseMedia tags = %+v, expected EXT-X-ALLOW-CACHE", p.Tags)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		playlist string
+		master   bool
+	}{
+		{"empty", "", false},
+		{"no header", "#EXTINF:6,\nseg.ts\n", false},
+		{"bad extinf", "#EXTM3U\n#EXTINF:six,\nseg.ts\n", false},
+		{"uri without extinf", "#EXTM3U\nseg.ts\n", false},
+		{"bad bandwidth", "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=fast\n720.m3u8\n", true},
+		{"bad resolution", "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1,RESOLUTION=720p\n720.m3u8\n", true},
+		{"unterminated", "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1,CODECS=\"avc1\n720.m3u8\n", true},
+		{"no uri", "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\n", true},
+	}
+
+	for _, c := range cases {
+		var err error
+		if c.master {
+			_, err = ParseMaster(strings.NewReader(c.playlist))
+		} else {
+			_, err = ParseMedia(strings.NewReader(c.playlist))
+		}
+
+		if err == nil {
+			t.Errorf("%v: expected error", c.name)
+		}
+	}
+
+	_, err := ParseMedia(strings.NewReader("<html></html>"))
+	if err != ErrNotPlaylist {
+		t.Errorf("ParseMedia error = %v, expected %v", err, ErrNotPlaylist)
+	}
+}
@@ -0,0 +1,328 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultSegmentDuration = 6
+
+var (
+	// ErrNoVideoStream is returned by LadderPlanner when source has no video stream
+	ErrNoVideoStream = errors.New("filespot: source has no video stream")
+	// ErrEmptyLadder is returned by LadderPlanner when no preset fits the source
+	ErrEmptyLadder = errors.New("filespot: no preset fits the source")
+)
+
+// LadderPlanner proposes adaptive bitrate ladder for HLS of source Object.
+// Presets which upscale the source or exceed its bitrate are skipped,
+// one preset is chosen per resolution.
+type LadderPlanner struct {
+	Catalog *PresetCatalog
+	// Container restricts presets when set
+	Container string
+	// SegmentDuration of HLS in seconds, 6 by default
+	SegmentDuration int
+	// MaxRungs limits ladder to the highest rungs when set
+	MaxRungs int
+}
+
+// LadderRung is a rendition of ladder
+type LadderRung struct {
+	Preset Preset
+	Width  int
+	Height int
+	// BitRate of video in kbit/s
+	BitRate int
+}
+
+// LadderDecision explains why preset was chosen or skipped
+type LadderDecision struct {
+	Preset Preset
+	Chosen bool
+	Reason string
+}
+
+// Ladder is result of LadderPlanner.Plan
+type Ladder struct {
+	// Rungs ordered from the highest resolution
+	Rungs           []LadderRung
+	SegmentDuration int
+	// Decisions about every preset, in order of presets
+	Decisions []LadderDecision
+	// Notes about source which affect the ladder
+	Notes []string
+}
+
+// ladderSource is media info of source
+type ladderSource struct {
+	width, height int
+	fps           float64
+	// bitRate of video in kbit/s, zero when unknown
+	bitRate int
+}
+
+// NewLadderPlanner returns LadderPlanner using presets of catalog
+func NewLadderPlanner(catalog *PresetCatalog) *LadderPlanner {
+	return &LadderPlanner{
+		Catalog: catalog,
+	}
+}
+
+// Plan proposes ladder of source. The ladder with decisions is returned with ErrEmptyLadder too.
+func (p *LadderPlanner) Plan(ctx context.Context, source *Object) (*Ladder, error) {
+	stream := objectVideoStream(source)
+	if stream == nil || stream.Width == 0 || stream.Height == 0 {
+		return nil, ErrNoVideoStream
+	}
+
+	presets, err := p.Catalog.Presets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	src := ladderSource{
+		width:   int(stream.Width),
+		height:  int(stream.Height),
+		fps:     float64(stream.FPS),
+		bitRate: videoBitRate(source, stream),
+	}
+
+	segmentDuration := p.SegmentDuration
+	if segmentDuration <= 0 {
+		segmentDuration = defaultSegmentDuration
+	}
+
+	ladder := &Ladder{
+		SegmentDuration: segmentDuration,
+		Decisions:       make([]LadderDecision, len(presets)),
+		Notes:           sourceNotes(source, &src),
+	}
+
+	// best candidate index per short side of preset
+	best := make(map[int]int)
+	for i, preset := range presets {
+		ladder.Decisions[i].Preset = preset
+
+		reason, ok := p.fits(&preset, &src)
+		ladder.Decisions[i].Reason = reason
+		if !ok {
+			continue
+		}
+
+		side := shortSide(preset.Video.MaxWidth, preset.Video.MaxHeight)
+		j, seen := best[side]
+		if !seen {
+			best[side] = i
+			continue
+		}
+
+		if better(&preset, &presets[j], &src) {
+			ladder.Decisions[j].Reason = fmt.Sprintf("preset %q is preferred for the same resolution", presets[i].Name)
+			best[side] = i
+		} else {
+			ladder.Decisions[i].Reason = fmt.Sprintf("preset %q is preferred for the same resolution", presets[j].Name)
+		}
+	}
+
+	for _, i := range best {
+		preset := presets[i]
+		ladder.Rungs = append(ladder.Rungs, LadderRung{
+			Preset:  preset,
+			Width:   preset.Video.MaxWidth,
+			Height:  preset.Video.MaxHeight,
+			BitRate: preset.Video.BitRate,
+		})
+	}
+
+	sort.Slice(ladder.Rungs, func(i, j int) bool {
+		return shortSide(ladder.Rungs[i].Width, ladder.Rungs[i].Height) > shortSide(ladder.Rungs[j].Width, ladder.Rungs[j].Height)
+	})
+
+	if p.MaxRungs > 0 && len(ladder.Rungs) > p.MaxRungs {
+		for _, rung := range ladder.Rungs[p.MaxRungs:] {
+			ladder.decision(rung.Preset.ID).Reason = fmt.Sprintf("ladder is limited to %d rungs", p.MaxRungs)
+		}
+		ladder.Rungs = ladder.Rungs[:p.MaxRungs]
+	}
+
+	for _, rung := range ladder.Rungs {
+		decision := ladder.decision(rung.Preset.ID)
+		decision.Chosen = true
+		decision.Reason = fmt.Sprintf("%dx%d at %d kbit/s fits source %dx%d", rung.Width, rung.Height, rung.BitRate, src.width, src.height)
+		if src.bitRate > 0 {
+			decision.Reason += fmt.Sprintf(" at %d kbit/s", src.bitRate)
+		}
+	}
+
+	if len(ladder.Rungs) == 0 {
+		return ladder, ErrEmptyLadder
+	}
+
+	return ladder, nil
+}
+
+// HLS plans ladder of source and builds HLS of it via TranscoderService.HLS
+func (p *LadderPlanner) HLS(ctx context.Context, source *Object) (*Transcoder, *Ladder, error) {
+	ladder, err := p.Plan(ctx, source)
+	if err != nil {
+		return nil, ladder, err
+	}
+
+	transcoder, _, err := p.Catalog.Transcoder.HLS(ctx, source.ID, ladder.HLSRequest())
+	if err != nil {
+		return nil, ladder, err
+	}
+
+	return transcoder, ladder, nil
+}
+
+// HLSRequest returns request building HLS of the ladder
+func (l *Ladder) HLSRequest() *TranscoderHLSRequest {
+	presets := make([]string, len(l.Rungs))
+	for i, rung := range l.Rungs {
+		presets[i] = rung.Preset.ID
+	}
+
+	return &TranscoderHLSRequest{
+		Presets:         presets,
+		SegmentDuration: l.SegmentDuration,
+	}
+}
+
+// Explain returns human readable decisions about presets
+func (l *Ladder) Explain() string {
+	var sb strings.Builder
+
+	for _, note := range l.Notes {
+		fmt.Fprintf(&sb, "note: %v\n", note)
+	}
+
+	for _, d := range l.Decisions {
+		mark := "skip"
+		if d.Chosen {
+			mark = "use "
+		}
+
+		fmt.Fprintf(&sb, "%v %v (%v): %v\n", mark, d.Preset.Name, d.Preset.ID, d.Reason)
+	}
+
+	fmt.Fprintf(&sb, "segment duration: %ds\n", l.SegmentDuration)
+
+	return sb.String()
+}
+
+// decision returns decision of preset
+func (l *Ladder) decision(id string) *LadderDecision {
+	for i := range l.Decisions {
+		if l.Decisions[i].Preset.ID == id {
+			return &l.Decisions[i]
+		}
+	}
+
+	return nil
+}
+
+// fits reports whether preset can be a rung and why
+func (p *LadderPlanner) fits(preset *Preset, src *ladderSource) (string, bool) {
+	if p.Container != "" && !strings.EqualFold(preset.Container, p.Container) {
+		return fmt.Sprintf("container %v isn't %v", preset.Container, p.Container), false
+	}
+
+	video := preset.Video
+	if video == nil || video.MaxWidth == 0 || video.MaxHeight == 0 {
+		return "preset has no video resolution", false
+	}
+
+	side, srcSide := shortSide(video.MaxWidth, video.MaxHeight), shortSide(src.width, src.height)
+	if side > srcSide {
+		return fmt.Sprintf("%dx%d would upscale source %dx%d", video.MaxWidth, video.MaxHeight, src.width, src.height), false
+	}
+
+	if src.bitRate > 0 && video.BitRate > src.bitRate {
+		return fmt.Sprintf("%d kbit/s is above source %d kbit/s", video.BitRate, src.bitRate), false
+	}
+
+	return "", true
+}
+
+// better reports whether preset a suits source better than b of the same resolution:
+// frame rate not above the source wins, then the higher bitrate
+func better(a, b *Preset, src *ladderSource) bool {
+	aFPS, bFPS := fpsFits(a.Video.FPS, src.fps), fpsFits(b.Video.FPS, src.fps)
+	if aFPS != bFPS {
+		return aFPS
+	}
+
+	return a.Video.BitRate > b.Video.BitRate
+}
+
+// fpsFits reports whether preset frame rate doesn't exceed source one
+func fpsFits(fps, src float64) bool {
+	return fps == 0 || src == 0 || fps <= src*1.01
+}
+
+// videoBitRate returns source video bitrate in kbit/s, zero when unknown
+func videoBitRate(source *Object, stream *ObjectVideoStream) int {
+	if stream.BitRate > 0 {
+		return int(stream.BitRate / 1000)
+	}
+
+	if source.Advanced.Format == nil || source.Advanced.Format.BitRate == 0 {
+		return 0
+	}
+
+	total := int(source.Advanced.Format.BitRate)
+	for _, audio := range source.Advanced.AudioStreams {
+		total -= int(audio.BitRate)
+	}
+
+	if total <= 0 {
+		return 0
+	}
+
+	return total / 1000
+}
+
+// sourceNotes describes source properties affecting the ladder
+func sourceNotes(source *Object, src *ladderSource) []string {
+	notes := []string{fmt.Sprintf("source video %dx%d", src.width, src.height)}
+
+	if src.fps > 0 {
+		notes[0] += fmt.Sprintf(" at %.3g fps", src.fps)
+	}
+
+	if src.bitRate > 0 {
+		notes[0] += fmt.Sprintf(", %d kbit/s", src.bitRate)
+	} else {
+		notes = append(notes, "source bitrate is unknown, presets aren't limited by bitrate")
+	}
+
+	audio := source.Advanced.AudioStreams
+	switch {
+	case len(audio) == 0:
+		notes = append(notes, "source has no audio, renditions will have silent or no audio track")
+	case audio[0].Channels == 1:
+		notes = append(notes, "source audio is mono, stereo presets will duplicate the channel")
+	default:
+		layout := audio[0].ChannelLayout
+		if layout == "" {
+			layout = fmt.Sprintf("%d channels", audio[0].Channels)
+		}
+		notes = append(notes, "source audio is "+layout)
+	}
+
+	return notes
+}
+
+// shortSide returns the smaller dimension, it identifies resolution of portrait and landscape video
+func shortSide(width, height int) int {
+	if width < height {
+		return width
+	}
+
+	return height
+}
@@ -0,0 +1,173 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const ladderPresets = `{
+    "code": 200,
+    "presets": [
+        {"id": "566b0fbf044dfe64f2000001", "name": "2160p", "container": "mp4", "video": {"max_width": "3840", "max_height": "2160", "bit_rate": "12000"}},
+        {"id": "566b0fbf044dfe64f2000002", "name": "1080p", "container": "mp4", "video": {"max_width": "1920", "max_height": "1080", "bit_rate": "5000"}},
+        {"id": "566b0fbf044dfe64f2000003", "name": "720p", "container": "mp4", "video": {"max_width": "1280", "max_height": "720", "bit_rate": "2500", "fps": "25"}},
+        {"id": "566b0fbf044dfe64f2000004", "name": "720p60", "container": "mp4", "video": {"max_width": "1280", "max_height": "720", "bit_rate": "3500", "fps": "60"}},
+        {"id": "566b0fbf044dfe64f2000005", "name": "480p", "container": "mp4", "video": {"max_width": "854", "max_height": "480", "bit_rate": "1200"}},
+        {"id": "566b0fbf044dfe64f2000006", "name": "webm 480p", "container": "webm", "video": {"max_width": "854", "max_height": "480", "bit_rate": "1000"}},
+        {"id": "566b0fbf044dfe64f2000007", "name": "audio", "container": "mp3", "video": null}
+    ]
+}`
+
+func ladderSourceObject(bitRate uint32) *Object {
+	return &Object{
+		ID: "5b4e6ba50e47cf3a54ae0e2b",
+		Advanced: &ObjectAdvanced{
+			VideoStreams: []ObjectVideoStream{{Width: 1920, Height: 1080, FPS: 25, BitRate: bitRate}},
+			AudioStreams: []ObjectAudioStream{{Channels: 2, ChannelLayout: "stereo", BitRate: 128000}},
+			Format:       &ObjectFormat{BitRate: bitRate + 128000},
+		},
+	}
+}
+
+func TestLadderPlanner_Plan(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ladderPresets)
+	})
+
+	planner := NewLadderPlanner(NewPresetCatalog(client.Transcoder))
+	planner.Container = "mp4"
+	planner.SegmentDuration = 4
+
+	ladder, err := planner.Plan(ctx, ladderSourceObject(4000000))
+	if err != nil {
+		t.Fatalf("LadderPlanner.Plan returned error: %v", err)
+	}
+
+	expected := &TranscoderHLSRequest{
+		Presets:         []string{"566b0fbf044dfe64f2000003", "566b0fbf044dfe64f2000005"},
+		SegmentDuration: 4,
+	}
+
+	if hls := ladder.HLSRequest(); !reflect.DeepEqual(hls, expected) {
+		t.Errorf("Ladder.HLSRequest = %+v, expected %+v", hls, expected)
+	}
+
+	explain := ladder.Explain()
+	for _, reason := range []string{
+		"3840x2160 would upscale source 1920x1080",
+		"5000 kbit/s is above source 4000 kbit/s",
+		`preset "720p" is preferred for the same resolution`,
+		"container webm isn't mp4",
+		"container mp3 isn't mp4",
+		"source audio is stereo",
+	} {
+		if !strings.Contains(explain, reason) {
+			t.Errorf("Ladder.Explain = %v, expected to contain %q", explain, reason)
+		}
+	}
+}
+
+func TestLadderPlanner_PlanUnknownBitRate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ladderPresets)
+	})
+
+	planner := NewLadderPlanner(NewPresetCatalog(client.Transcoder))
+	planner.MaxRungs = 2
+
+	source := ladderSourceObject(0)
+	source.Advanced.Format = nil
+
+	ladder, err := planner.Plan(ctx, source)
+	if err != nil {
+		t.Fatalf("LadderPlanner.Plan returned error: %v", err)
+	}
+
+	expected := []string{"566b0fbf044dfe64f2000002", "566b0fbf044dfe64f2000003"}
+	if presets := ladder.HLSRequest().Presets; !reflect.DeepEqual(presets, expected) {
+		t.Errorf("Ladder presets = %v, expected %v", presets, expected)
+	}
+
+	if ladder.SegmentDuration != defaultSegmentDuration {
+		t.Errorf("Ladder.SegmentDuration = %v, expected %v", ladder.SegmentDuration, defaultSegmentDuration)
+	}
+
+	explain := ladder.Explain()
+	for _, reason := range []string{"ladder is limited to 2 rungs", "preset has no video resolution", "source bitrate is unknown"} {
+		if !strings.Contains(explain, reason) {
+			t.Errorf("Ladder.Explain = %v, expected to contain %q", explain, reason)
+		}
+	}
+}
+
+func TestLadderPlanner_PlanErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ladderPresets)
+	})
+
+	planner := NewLadderPlanner(NewPresetCatalog(client.Transcoder))
+
+	_, err := planner.Plan(ctx, &Object{Advanced: &ObjectAdvanced{}})
+	if err != ErrNoVideoStream {
+		t.Errorf("LadderPlanner.Plan error = %v, expected %v", err, ErrNoVideoStream)
+	}
+
+	source := ladderSourceObject(500000)
+	source.Advanced.VideoStreams[0].Width = 320
+	source.Advanced.VideoStreams[0].Height = 240
+
+	ladder, err := planner.Plan(ctx, source)
+	if err != ErrEmptyLadder || ladder == nil || len(ladder.Decisions) != 7 {
+		t.Errorf("LadderPlanner.Plan = %v, %v, expected decisions with %v", ladder, err, ErrEmptyLadder)
+	}
+}
+
+func TestLadderPlanner_HLS(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ladderPresets)
+	})
+
+	mux.HandleFunc("/1/transcoder/hls/5b4e6ba50e47cf3a54ae0e2b", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TranscoderHLSRequest)
+		json.NewDecoder(r.Body).Decode(v)
+
+		expected := &TranscoderHLSRequest{
+			Presets:         []string{"566b0fbf044dfe64f2000003", "566b0fbf044dfe64f2000005"},
+			SegmentDuration: defaultSegmentDuration,
+		}
+
+		if !reflect.DeepEqual(v, expected) {
+			t.Errorf("Transcoder.HLS request = %+v, expected %+v", v, expected)
+		}
+
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "56ea79e2534b4423135ebfd3"}`)
+	})
+
+	planner := NewLadderPlanner(NewPresetCatalog(client.Transcoder))
+	planner.Container = "mp4"
+
+	transcoder, ladder, err := planner.HLS(ctx, ladderSourceObject(4000000))
+	if err != nil {
+		t.Fatalf("LadderPlanner.HLS returned error: %v", err)
+	}
+
+	if transcoder.TaskID != "56ea79e2534b4423135ebfd3" || len(ladder.Rungs) != 2 {
+		t.Errorf("LadderPlanner.HLS = %+v, %+v, expected task 56ea79e2534b4423135ebfd3 of 2 rungs", transcoder, ladder)
+	}
+}
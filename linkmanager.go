@@ -0,0 +1,261 @@
+package filespot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultLinkTTL = time.Hour
+
+// LinkManager keeps a valid temporary Link for every requested Object.
+// Links are created via TempService.Create before the cached one expires
+// and superseded links are deleted via TempService.Delete once nobody relies on them.
+// LinkManager is safe for concurrent use.
+type LinkManager struct {
+	Temp TempService
+	// TTL of created links, one hour by default
+	TTL time.Duration
+	// RenewBefore renews links in Run when they expire sooner, TTL/4 by default
+	RenewBefore time.Duration
+	// Secure and Geo are applied to created links
+	Secure bool
+	Geo    Geo
+
+	mu      sync.Mutex
+	entries map[string]*linkEntry
+	retired []retiredLink
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// linkEntry is an active link of Object
+type linkEntry struct {
+	mu   sync.Mutex
+	link *Link
+	// promised is the latest time callers were told the link is valid
+	promised time.Time
+}
+
+// retiredLink is a superseded link waiting for deletion
+type retiredLink struct {
+	id    string
+	until time.Time
+}
+
+// NewLinkManager returns LinkManager creating links with ttl
+func NewLinkManager(temp TempService, ttl time.Duration) *LinkManager {
+	return &LinkManager{
+		Temp: temp,
+		TTL:  ttl,
+	}
+}
+
+// Link returns a link of objectID valid for at least minValidity.
+// Cached link is returned when it lives long enough, a new one is created otherwise.
+func (m *LinkManager) Link(ctx context.Context, objectID string, minValidity time.Duration) (*Link, error) {
+	entry := m.entry(objectID)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	now := m.clock()
+	until := now.Add(minValidity)
+
+	if entry.link != nil && !linkExpires(entry.link, until) {
+		if until.After(entry.promised) {
+			entry.promised = until
+		}
+		return entry.link, nil
+	}
+
+	link, err := m.create(ctx, objectID, now, minValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	m.replace(entry, link, until)
+
+	return link, nil
+}
+
+// Renew replaces links expiring within RenewBefore and deletes superseded links
+// which are not promised to anybody anymore. It returns the first error.
+func (m *LinkManager) Renew(ctx context.Context) error {
+	var firstErr error
+
+	now := m.clock()
+	renewBefore := m.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = m.ttl() / 4
+	}
+
+	for objectID, entry := range m.snapshot() {
+		entry.mu.Lock()
+		if entry.link != nil && linkExpires(entry.link, now.Add(renewBefore)) {
+			link, err := m.create(ctx, objectID, now, 0)
+			if err == nil {
+				m.replace(entry, link, now)
+			} else if firstErr == nil {
+				firstErr = err
+			}
+		}
+		entry.mu.Unlock()
+	}
+
+	if err := m.deleteRetired(ctx, now, false); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	return firstErr
+}
+
+// Run calls Renew every interval until ctx is done
+func (m *LinkManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Renew(ctx)
+		}
+	}
+}
+
+// Close deletes all managed links
+func (m *LinkManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	for _, entry := range m.entries {
+		if entry.link != nil {
+			m.retired = append(m.retired, retiredLink{id: entry.link.ID})
+		}
+	}
+	m.entries = nil
+	m.mu.Unlock()
+
+	return m.deleteRetired(ctx, m.clock(), true)
+}
+
+// entry returns linkEntry of objectID
+func (m *LinkManager) entry(objectID string) *linkEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = make(map[string]*linkEntry)
+	}
+
+	entry, ok := m.entries[objectID]
+	if !ok {
+		entry = new(linkEntry)
+		m.entries[objectID] = entry
+	}
+
+	return entry
+}
+
+// snapshot returns copy of entries
+func (m *LinkManager) snapshot() map[string]*linkEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make(map[string]*linkEntry, len(m.entries))
+	for k, v := range m.entries {
+		entries[k] = v
+	}
+
+	return entries
+}
+
+// create creates link of objectID living at least TTL and minValidity
+func (m *LinkManager) create(ctx context.Context, objectID string, now time.Time, minValidity time.Duration) (*Link, error) {
+	ttl := m.ttl()
+	if minValidity > ttl {
+		ttl = minValidity
+	}
+
+	linkCreateRequest := &LinkCreateRequest{
+		ObjectID: objectID,
+		Exp:      int(now.Add(ttl).Unix()),
+		Secure:   m.Secure,
+		Geo:      m.Geo,
+	}
+
+	link, _, err := m.Temp.Create(ctx, linkCreateRequest)
+	return link, err
+}
+
+// replace sets link of entry and retires the previous one, entry must be locked
+func (m *LinkManager) replace(entry *linkEntry, link *Link, promised time.Time) {
+	if entry.link != nil && entry.link.ID != link.ID {
+		m.mu.Lock()
+		m.retired = append(m.retired, retiredLink{id: entry.link.ID, until: entry.promised})
+		m.mu.Unlock()
+	}
+
+	entry.link = link
+	entry.promised = promised
+}
+
+// deleteRetired deletes superseded links promised until now, or all of them when force is set
+func (m *LinkManager) deleteRetired(ctx context.Context, now time.Time, force bool) error {
+	m.mu.Lock()
+	var due, keep []retiredLink
+	for _, r := range m.retired {
+		if force || !r.until.After(now) {
+			due = append(due, r)
+		} else {
+			keep = append(keep, r)
+		}
+	}
+	m.retired = keep
+	m.mu.Unlock()
+
+	var firstErr error
+	for _, r := range due {
+		_, err := m.Temp.Delete(ctx, r.id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			// retry on the next call
+			m.mu.Lock()
+			m.retired = append(m.retired, r)
+			m.mu.Unlock()
+		}
+	}
+
+	return firstErr
+}
+
+// ttl returns TTL of created links
+func (m *LinkManager) ttl() time.Duration {
+	if m.TTL <= 0 {
+		return defaultLinkTTL
+	}
+
+	return m.TTL
+}
+
+// clock returns current time
+func (m *LinkManager) clock() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+
+	return time.Now()
+}
+
+// linkExpires reports whether link expires before t, Exp of endless links is zero
+func linkExpires(link *Link, t time.Time) bool {
+	if link.Exp == 0 {
+		return false
+	}
+
+	return time.Unix(int64(link.Exp), 0).Before(t)
+}
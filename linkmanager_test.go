@@ -0,0 +1,153 @@
+package filespot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTemp is in-memory TempService
+type fakeTemp struct {
+	mu      sync.Mutex
+	links   map[string]*Link
+	created int
+	deleted []string
+}
+
+func newFakeTemp() *fakeTemp {
+	return &fakeTemp{links: make(map[string]*Link)}
+}
+
+func (f *fakeTemp) List(ctx context.Context, params *TempListParams) ([]Link, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var links []Link
+	for _, l := range f.links {
+		links = append(links, *l)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+
+	return links, nil, nil
+}
+
+func (f *fakeTemp) Get(ctx context.Context, id string) (*Link, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.links[id], nil, nil
+}
+
+func (f *fakeTemp) Create(ctx context.Context, linkCreateRequest *LinkCreateRequest) (*Link, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.created++
+	link := &Link{
+		ID:       fmt.Sprintf("link%d", f.created),
+		ObjectID: linkCreateRequest.ObjectID,
+		Href:     fmt.Sprintf("cdn.platformcraft.ru/temp/link%d", f.created),
+		Secure:   linkCreateRequest.Secure,
+		Exp:      linkCreateRequest.Exp,
+		Geo:      linkCreateRequest.Geo,
+	}
+	if linkCreateRequest.Endless {
+		link.Exp = 0
+	}
+	f.links[link.ID] = link
+
+	return link, nil, nil
+}
+
+func (f *fakeTemp) Delete(ctx context.Context, id string) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.links, id)
+	f.deleted = append(f.deleted, id)
+
+	return nil, nil
+}
+
+func (f *fakeTemp) Secure(ctx context.Context, id string, secureLinkRequest *SecureLinkRequest) (*SecureLink, *http.Response, error) {
+	return nil, nil, nil
+}
+
+func TestLinkManagerLink(t *testing.T) {
+	temp := newFakeTemp()
+	manager := NewLinkManager(temp, time.Hour)
+
+	now := time.Unix(1558030392, 0)
+	manager.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.Link(ctx, "56787f0c044dfe226b000001", 10*time.Minute)
+		}()
+	}
+	wg.Wait()
+
+	if temp.created != 1 {
+		t.Errorf("LinkManager.Link created %v links, expected %v", temp.created, 1)
+	}
+
+	link, _ := manager.Link(ctx, "56787f0c044dfe226b000001", 10*time.Minute)
+	if link.ID != "link1" {
+		t.Errorf("LinkManager.Link = %v, expected %v", link.ID, "link1")
+	}
+
+	// cached link is valid for 45 minutes, a longer request needs a new link
+	now = now.Add(15 * time.Minute)
+	link, _ = manager.Link(ctx, "56787f0c044dfe226b000001", 50*time.Minute)
+	if link.ID != "link2" {
+		t.Errorf("LinkManager.Link = %v, expected %v", link.ID, "link2")
+	}
+
+	if exp := now.Add(time.Hour).Unix(); int64(link.Exp) != exp {
+		t.Errorf("LinkManager.Link Exp = %v, expected %v", link.Exp, exp)
+	}
+}
+
+func TestLinkManagerRenew(t *testing.T) {
+	temp := newFakeTemp()
+	manager := NewLinkManager(temp, time.Hour)
+
+	now := time.Unix(1558030392, 0)
+	manager.now = func() time.Time { return now }
+
+	manager.Link(ctx, "56787f0c044dfe226b000001", 55*time.Minute)
+
+	// link1 expires in 10 minutes and is still promised for 5 minutes
+	now = now.Add(50 * time.Minute)
+	if err := manager.Renew(ctx); err != nil {
+		t.Errorf("LinkManager.Renew returned error: %v", err)
+	}
+
+	link, _ := manager.Link(ctx, "56787f0c044dfe226b000001", time.Minute)
+	if link.ID != "link2" {
+		t.Errorf("LinkManager.Link = %v, expected %v", link.ID, "link2")
+	}
+
+	if len(temp.deleted) != 0 {
+		t.Errorf("LinkManager.Renew deleted %v, expected none", temp.deleted)
+	}
+
+	now = now.Add(5 * time.Minute)
+	manager.Renew(ctx)
+
+	if len(temp.deleted) != 1 || temp.deleted[0] != "link1" {
+		t.Errorf("LinkManager.Renew deleted %v, expected %v", temp.deleted, []string{"link1"})
+	}
+
+	manager.Close(ctx)
+	if len(temp.links) != 0 {
+		t.Errorf("LinkManager.Close left %v links, expected none", len(temp.links))
+	}
+}
@@ -0,0 +1,198 @@
+package filespot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultLogBodyLimit = 2048
+	redacted            = "REDACTED"
+)
+
+// redactedParams are query params which never reach the log
+var redactedParams = []string{"hash", "apiuserid"}
+
+// LogValue implements slog.LogValuer and hides credentials of Client
+func (c *Client) LogValue() slog.Value {
+	baseURL := ""
+	if c.BaseURL != nil {
+		baseURL = c.BaseURL.String()
+	}
+
+	return slog.GroupValue(
+		slog.String("base_url", baseURL),
+		slog.String("user_agent", c.UserAgent),
+		slog.String("api_user_id", redacted),
+		slog.String("api_user_key", redacted),
+	)
+}
+
+// logEnabled reports whether Logger accepts records of level
+func (c *Client) logEnabled(ctx context.Context, level slog.Level) bool {
+	return c.Logger != nil && c.Logger.Enabled(ctx, level)
+}
+
+// logRequest writes a record about request attempt
+func (c *Client) logRequest(ctx context.Context, req *http.Request, info *RequestInfo) {
+	level := slog.LevelInfo
+	switch {
+	case info.Status == 0 && info.Err != nil:
+		level = slog.LevelError
+	case info.Err != nil:
+		level = slog.LevelWarn
+	}
+
+	if !c.logEnabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", info.Method),
+		slog.String("path", info.Path),
+		slog.String("endpoint", info.Endpoint),
+		slog.String("url", c.redactURL(req.URL)),
+		slog.Int("status", info.Status),
+		slog.Duration("latency", info.Latency),
+		slog.Int("attempt", info.Attempt),
+	}
+	if info.ErrorCode != 0 {
+		attrs = append(attrs, slog.Uint64("error_code", uint64(info.ErrorCode)))
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", c.redact(info.Err.Error())))
+	}
+
+	c.Logger.LogAttrs(ctx, level, "filespot request", attrs...)
+}
+
+// logRequestBody dumps request body at debug level
+func (c *Client) logRequestBody(ctx context.Context, req *http.Request) {
+	if !c.LogBodies || !c.logEnabled(ctx, slog.LevelDebug) || req.GetBody == nil {
+		return
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return
+	}
+
+	c.Logger.LogAttrs(ctx, slog.LevelDebug, "filespot request body",
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("body", c.dumpBody(req.Header.Get("Content-Type"), b)),
+	)
+}
+
+// logResponseBody dumps response body at debug level.
+// Body of resp is replaced with a buffered copy so it can be decoded afterwards.
+func (c *Client) logResponseBody(ctx context.Context, resp *http.Response) {
+	if !c.LogBodies || !c.logEnabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return
+	}
+
+	c.Logger.LogAttrs(ctx, slog.LevelDebug, "filespot response body",
+		slog.String("method", resp.Request.Method),
+		slog.String("path", resp.Request.URL.Path),
+		slog.Int("status", resp.StatusCode),
+		slog.String("body", c.dumpBody(resp.Header.Get("Content-Type"), b)),
+	)
+}
+
+// dumpBody returns printable and truncated body with multipart files elided
+func (c *Client) dumpBody(contentType string, b []byte) string {
+	s := string(b)
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		s = dumpMultipart(b, params["boundary"])
+	}
+
+	limit := c.LogBodyLimit
+	if limit <= 0 {
+		limit = defaultLogBodyLimit
+	}
+
+	s = c.redact(s)
+	if len(s) > limit {
+		s = s[:limit] + fmt.Sprintf("... (%d bytes truncated)", len(s)-limit)
+	}
+
+	return s
+}
+
+// dumpMultipart returns multipart fields as text, file contents are replaced with their size
+func dumpMultipart(b []byte, boundary string) string {
+	var sb strings.Builder
+
+	mr := multipart.NewReader(bytes.NewReader(b), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sb.WriteString("<malformed multipart>")
+			break
+		}
+
+		if part.FileName() != "" {
+			n, _ := io.Copy(io.Discard, part)
+			fmt.Fprintf(&sb, "%v=<file %q: %d bytes elided>\n", part.FormName(), part.FileName(), n)
+			continue
+		}
+
+		value, _ := io.ReadAll(part)
+		fmt.Fprintf(&sb, "%v=%s\n", part.FormName(), value)
+	}
+
+	return sb.String()
+}
+
+// redactURL returns URL without authentication params
+func (c *Client) redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	r := *u
+	q := r.Query()
+	for _, k := range redactedParams {
+		if q.Get(k) != "" {
+			q.Set(k, redacted)
+		}
+	}
+	r.RawQuery = q.Encode()
+
+	return c.redact(r.String())
+}
+
+// redact removes APIUserKey from s
+func (c *Client) redact(s string) string {
+	if c.APIUserKey == "" {
+		return s
+	}
+
+	return strings.Replace(s, c.APIUserKey, redacted, -1)
+}
@@ -0,0 +1,121 @@
+package filespot
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func setupLogger(bodies bool) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	client.Logger = slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client.LogBodies = bodies
+
+	return buf
+}
+
+func TestLogRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/storage", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"storage": {"used": 1, "limit": 2}}`)
+	})
+
+	buf := setupLogger(false)
+	_, _, err := client.Storage.Get(ctx)
+	if err != nil {
+		t.Errorf("Storage.Get returned error: %v", err)
+	}
+
+	out := buf.String()
+	for _, s := range []string{"method=GET", "path=/1/storage", "status=200", "attempt=1", "latency="} {
+		if !strings.Contains(out, s) {
+			t.Errorf("log = %v, expected to contain %v", out, s)
+		}
+	}
+
+	for _, s := range []string{"apiuserid=" + apiuserid, apiuserkey, "body="} {
+		if strings.Contains(out, s) {
+			t.Errorf("log = %v, expected not to contain %v", out, s)
+		}
+	}
+}
+
+func TestLogRequestErrorCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/storage", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 403, "status": "fail", "msg_user": "Forbidden"}`, http.StatusForbidden)
+	})
+
+	buf := setupLogger(false)
+	_, _, err := client.Storage.Get(ctx)
+	if err == nil {
+		t.Error("Storage.Get returns without expected error")
+	}
+
+	out := buf.String()
+	for _, s := range []string{"level=WARN", "status=403", "error_code=403"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("log = %v, expected to contain %v", out, s)
+		}
+	}
+}
+
+func TestLogBodies(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"object": {"id": "56787f0c044dfe226b000001", "description": "%v"}}`, apiuserkey)
+	})
+
+	buf := setupLogger(true)
+	object, _, err := client.Objects.Create(ctx, &ObjectCreateRequest{File: "test.mp4", Name: "test.mp4"})
+	if err != nil {
+		t.Errorf("Objects.Create returned error: %v", err)
+	}
+
+	if object.ID != "56787f0c044dfe226b000001" {
+		t.Errorf("Objects.Create ID = %v, expected %v", object.ID, "56787f0c044dfe226b000001")
+	}
+
+	out := buf.String()
+	for _, s := range []string{"name=test.mp4", `file \"test.mp4\"`, "bytes elided", "56787f0c044dfe226b000001"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("log = %v, expected to contain %v", out, s)
+		}
+	}
+
+	if strings.Contains(out, apiuserkey) {
+		t.Errorf("log = %v, expected not to contain %v", out, apiuserkey)
+	}
+}
+
+func TestDumpBodyTruncate(t *testing.T) {
+	c := NewClient(apiuserid, apiuserkey)
+	c.LogBodyLimit = 4
+
+	body := c.dumpBody(mediaType, []byte(`{"name":"filespot"}`))
+	expected := `{"na... (15 bytes truncated)`
+
+	if body != expected {
+		t.Errorf("dumpBody = %v, expected %v", body, expected)
+	}
+}
+
+func TestClientLogValue(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+
+	logger.Info("client", "client", NewClient(apiuserid, apiuserkey))
+
+	if strings.Contains(buf.String(), apiuserkey) {
+		t.Errorf("log = %v, expected not to contain %v", buf.String(), apiuserkey)
+	}
+}
@@ -0,0 +1,224 @@
+// Package metrics exposes filespot API usage as prometheus metrics.
+//
+// Collector records every request of a filespot.Client and optionally
+// polls storage quota and active tasks:
+//
+//	c := filespot.NewClient(apiUserID, apiUserKey)
+//	m := metrics.NewCollector(c, "")
+//	prometheus.MustRegister(m)
+//	go m.Run(ctx, time.Minute)
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/droff/filespot"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultNamespace = "filespot"
+
+// Collector implements prometheus.Collector and filespot.RequestObserver
+type Collector struct {
+	client *filespot.Client
+
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+
+	storageUsed  prometheus.Gauge
+	storageLimit prometheus.Gauge
+	tasks        *prometheus.GaugeVec
+	pollErrors   prometheus.Counter
+	clockSkew    prometheus.GaugeFunc
+
+	// observer attached to client before the Collector
+	observer filespot.RequestObserver
+}
+
+// NewCollector returns Collector attached to client.
+// Namespace prefixes all metric names, "filespot" by default.
+func NewCollector(client *filespot.Client, namespace string) *Collector {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	c := &Collector{
+		client: client,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of API requests by method, endpoint and HTTP status.",
+		}, []string{"method", "endpoint", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_errors_total",
+			Help:      "Number of API error responses by endpoint and error code.",
+		}, []string{"method", "endpoint", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "API request latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_retries_total",
+			Help:      "Number of retried API requests.",
+		}, []string{"method", "endpoint"}),
+		storageUsed: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "storage_used_bytes",
+			Help:      "Used storage space.",
+		}),
+		storageLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "storage_limit_bytes",
+			Help:      "Storage space limit.",
+		}),
+		tasks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "tasks_in_progress",
+			Help:      "Number of download and transcoder tasks in progress.",
+		}, []string{"kind"}),
+		pollErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "poll_errors_total",
+			Help:      "Number of failed storage and tasks polls.",
+		}),
+	}
+
+	if client != nil {
+		c.observer = client.Observer
+		client.Observer = c
+
+		c.clockSkew = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "clock_skew_seconds",
+			Help:      "Measured offset of the API server clock.",
+		}, func() float64 {
+			return client.ClockSkew().Seconds()
+		})
+	}
+
+	return c
+}
+
+// ObserveRequest implements filespot.RequestObserver
+func (c *Collector) ObserveRequest(ctx context.Context, info *filespot.RequestInfo) {
+	status := strconv.Itoa(info.Status)
+	if info.Status == 0 {
+		status = "none"
+	}
+
+	c.requests.WithLabelValues(info.Method, info.Endpoint, status).Inc()
+	c.latency.WithLabelValues(info.Method, info.Endpoint).Observe(info.Latency.Seconds())
+
+	if info.ErrorCode != 0 {
+		code := strconv.FormatUint(uint64(info.ErrorCode), 10)
+		c.errors.WithLabelValues(info.Method, info.Endpoint, code).Inc()
+	}
+
+	if info.Attempt > 1 {
+		c.retries.WithLabelValues(info.Method, info.Endpoint).Inc()
+	}
+
+	if c.observer != nil {
+		c.observer.ObserveRequest(ctx, info)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// collectors returns all underlying metrics
+func (c *Collector) collectors() []prometheus.Collector {
+	collectors := []prometheus.Collector{
+		c.requests,
+		c.errors,
+		c.latency,
+		c.retries,
+		c.storageUsed,
+		c.storageLimit,
+		c.tasks,
+		c.pollErrors,
+	}
+	if c.clockSkew != nil {
+		collectors = append(collectors, c.clockSkew)
+	}
+
+	return collectors
+}
+
+// Poll updates storage and tasks gauges once
+func (c *Collector) Poll(ctx context.Context) error {
+	storage, _, err := c.client.Storage.Get(ctx)
+	if err != nil {
+		c.pollErrors.Inc()
+		return err
+	}
+
+	c.storageUsed.Set(float64(storage.Used))
+	c.storageLimit.Set(float64(storage.Limit))
+
+	downloadTasks, _, err := c.client.DownloadTasks.List(ctx, nil)
+	if err != nil {
+		c.pollErrors.Inc()
+		return err
+	}
+
+	c.tasks.WithLabelValues("download").Set(float64(inProgress(downloadTasks)))
+
+	transcoderTasks, _, err := c.client.TranscoderTasks.List(ctx, nil)
+	if err != nil {
+		c.pollErrors.Inc()
+		return err
+	}
+
+	c.tasks.WithLabelValues("transcoder").Set(float64(inProgress(transcoderTasks)))
+
+	return nil
+}
+
+// Run polls storage and tasks every interval until ctx is done.
+// Poll errors are counted by poll_errors_total and don't stop the loop.
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// inProgress returns number of tasks in progress
+func inProgress(tasks []filespot.Task) int {
+	n := 0
+	for _, t := range tasks {
+		if t.Status == filespot.TaskProgress {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/droff/filespot"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func setup(t *testing.T, mux *http.ServeMux) (*filespot.Client, *Collector) {
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := filespot.NewClient("test", "APIUserKey")
+	client.BaseURL, _ = url.Parse(server.URL)
+
+	return client, NewCollector(client, "")
+}
+
+func TestCollectorRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000001", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"object": {"id": "56787f0c044dfe226b000001"}}`)
+	})
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000002", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 404, "status": "fail"}`, http.StatusNotFound)
+	})
+
+	client, collector := setup(t, mux)
+
+	client.Objects.Get(context.Background(), "56787f0c044dfe226b000001")
+	client.Objects.Get(context.Background(), "56787f0c044dfe226b000002")
+
+	expected := `
+# HELP filespot_requests_total Number of API requests by method, endpoint and HTTP status.
+# TYPE filespot_requests_total counter
+filespot_requests_total{endpoint="/1/objects/{id}",method="GET",status="200"} 1
+filespot_requests_total{endpoint="/1/objects/{id}",method="GET",status="404"} 1
+# HELP filespot_api_errors_total Number of API error responses by endpoint and error code.
+# TYPE filespot_api_errors_total counter
+filespot_api_errors_total{code="404",endpoint="/1/objects/{id}",method="GET"} 1
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "filespot_requests_total", "filespot_api_errors_total")
+	if err != nil {
+		t.Errorf("Collector metrics: %v", err)
+	}
+
+	if n := testutil.CollectAndCount(collector, "filespot_request_duration_seconds"); n != 1 {
+		t.Errorf("Collector latency series = %v, expected %v", n, 1)
+	}
+}
+
+func TestCollectorRetries(t *testing.T) {
+	collector := NewCollector(nil, "test")
+	collector.ObserveRequest(context.Background(), &filespot.RequestInfo{Method: "GET", Endpoint: "/1/storage", Status: 200, Attempt: 2})
+
+	if v := testutil.ToFloat64(collector.retries); v != 1 {
+		t.Errorf("Collector retries = %v, expected %v", v, 1)
+	}
+}
+
+func TestCollectorPoll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/storage", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"storage": {"used": 97537237, "limit": 107374182}}`)
+	})
+	mux.HandleFunc("/1/download_tasks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tasks": [{"id": "1", "status": "Progress"}, {"id": "2", "status": "Completed"}]}`)
+	})
+	mux.HandleFunc("/1/transcoder_tasks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tasks": [{"id": "3", "status": "Progress"}, {"id": "4", "status": "Progress"}]}`)
+	})
+
+	_, collector := setup(t, mux)
+
+	err := collector.Poll(context.Background())
+	if err != nil {
+		t.Errorf("Collector.Poll returned error: %v", err)
+	}
+
+	expected := `
+# HELP filespot_storage_used_bytes Used storage space.
+# TYPE filespot_storage_used_bytes gauge
+filespot_storage_used_bytes 9.7537237e+07
+# HELP filespot_storage_limit_bytes Storage space limit.
+# TYPE filespot_storage_limit_bytes gauge
+filespot_storage_limit_bytes 1.07374182e+08
+# HELP filespot_tasks_in_progress Number of download and transcoder tasks in progress.
+# TYPE filespot_tasks_in_progress gauge
+filespot_tasks_in_progress{kind="download"} 1
+filespot_tasks_in_progress{kind="transcoder"} 2
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"filespot_storage_used_bytes", "filespot_storage_limit_bytes", "filespot_tasks_in_progress")
+	if err != nil {
+		t.Errorf("Collector metrics: %v", err)
+	}
+}
+
+func TestCollectorClockSkew(t *testing.T) {
+	_, collector := setup(t, http.NewServeMux())
+
+	if v := testutil.ToFloat64(collector.clockSkew); v != 0 {
+		t.Errorf("Collector clock skew = %v, expected %v", v, 0)
+	}
+}
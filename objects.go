@@ -17,7 +17,7 @@ const objectsBasePath = "/1/objects"
 // ObjectsService implements interface with API /objects endpoint.
 // See https://doc.platformcraft.ru/filespot/api/en/#objects
 type ObjectsService interface {
-	List(context.Context, interface{}) ([]Object, *http.Response, error)
+	List(context.Context, *ObjectsListParams) ([]Object, *http.Response, error)
 	Get(context.Context, string) (*Object, *http.Response, error)
 	Create(context.Context, *ObjectCreateRequest) (*Object, *http.Response, error)
 	Update(context.Context, string, *ObjectUpdateRequest) (*http.Response, error)
@@ -166,7 +166,7 @@ type ObjectsListParams struct {
 }
 
 // List returns list of all objects (files) in container
-func (c ObjectsCli) List(ctx context.Context, params interface{}) ([]Object, *http.Response, error) {
+func (c ObjectsCli) List(ctx context.Context, params *ObjectsListParams) ([]Object, *http.Response, error) {
 	path, err := addParams(objectsBasePath, params)
 	if err != nil {
 		return nil, nil, err
@@ -0,0 +1,56 @@
+package filespot
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// idSegment matches platformcraft object identifiers in request paths
+var idSegment = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+
+// RequestInfo describes a single API request attempt
+type RequestInfo struct {
+	Method string
+	// Path is the request path, e.g. /1/objects/56787f0c044dfe226b000001
+	Path string
+	// Endpoint is the Path with identifiers replaced by {id}, e.g. /1/objects/{id}
+	Endpoint string
+	// Status is HTTP status code, zero when no response was received
+	Status  int
+	Latency time.Duration
+	// Attempt starts from 1 and grows on every retry of the same request
+	Attempt int
+	// ErrorCode is ErrorResponse.Code of failed request
+	ErrorCode uint32
+	Err       error
+}
+
+// RequestObserver is notified about every API request attempt.
+// It's used to plug metrics and tracing into the Client.
+type RequestObserver interface {
+	ObserveRequest(context.Context, *RequestInfo)
+}
+
+// finishRequest reports request attempt to Logger and Observer
+func (c *Client) finishRequest(ctx context.Context, req *http.Request, info *RequestInfo) {
+	c.logRequest(ctx, req, info)
+
+	if c.Observer != nil {
+		c.Observer.ObserveRequest(ctx, info)
+	}
+}
+
+// endpointPattern returns path with identifiers replaced by {id}
+func endpointPattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if idSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,59 @@
+package filespot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type observerFunc func(context.Context, *RequestInfo)
+
+func (f observerFunc) ObserveRequest(ctx context.Context, info *RequestInfo) {
+	f(ctx, info)
+}
+
+func TestObserver(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000001", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 404, "status": "fail"}`, http.StatusNotFound)
+	})
+
+	var infos []*RequestInfo
+	client.Observer = observerFunc(func(ctx context.Context, info *RequestInfo) {
+		infos = append(infos, info)
+	})
+
+	_, _, err := client.Objects.Get(ctx, "56787f0c044dfe226b000001")
+	if err == nil {
+		t.Error("Objects.Get returns without expected error")
+	}
+
+	if len(infos) != 1 {
+		t.Fatalf("Observer calls = %v, expected %v", len(infos), 1)
+	}
+
+	info := infos[0]
+	expected := fmt.Sprintf("%v %v %v %v %v", http.MethodGet, "/1/objects/{id}", http.StatusNotFound, 404, 1)
+	got := fmt.Sprintf("%v %v %v %v %v", info.Method, info.Endpoint, info.Status, info.ErrorCode, info.Attempt)
+	if got != expected {
+		t.Errorf("RequestInfo = %v, expected %v", got, expected)
+	}
+}
+
+func TestEndpointPattern(t *testing.T) {
+	tests := map[string]string{
+		"/1/objects":                                            "/1/objects",
+		"/1/objects/56787f0c044dfe226b000001":                   "/1/objects/{id}",
+		"/1/temp/58ee48ca534b4409844c8f7a/secure":               "/1/temp/{id}/secure",
+		"/1/streams/rec/instant/start/5b0ef0f5534b44566dba3bbd": "/1/streams/rec/instant/start/{id}",
+	}
+
+	for path, expected := range tests {
+		if got := endpointPattern(path); got != expected {
+			t.Errorf("endpointPattern(%v) = %v, expected %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package filespot
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOEmbedCacheTTL         = 5 * time.Minute
+	defaultOEmbedNegativeCacheTTL = 30 * time.Second
+	defaultOEmbedCacheSize        = 1024
+)
+
+var (
+	frameTagSrc   = regexp.MustCompile(`(?i)\bsrc="([^"]*)"`)
+	frameTagSize  = regexp.MustCompile(`(?i)\s(width|height)="[^"]*"`)
+	frameTagStart = regexp.MustCompile(`(?i)^<iframe`)
+)
+
+// OEmbedProvider is http.Handler serving oEmbed responses for player URLs:
+//
+//	GET /oembed?url=https://video.platformcraft.ru/embed/<id>&maxwidth=480&format=xml
+//
+// Private players respond with 401 Unauthorized and geo restricted ones
+// with 403 Forbidden as oEmbed consumers can't enforce access rules.
+// See https://oembed.com/#section2.3
+type OEmbedProvider struct {
+	Players PlayersService
+	// Objects resolves source Object of the player to get video dimensions
+	// and screenshot Object to get thumbnail dimensions,
+	// 16:9 embed of default width is returned without it
+	Objects ObjectsService
+	// Hosts restricts accepted player URLs, any host is accepted by default
+	Hosts []string
+	// ProviderURL is included in responses when set
+	ProviderURL string
+	// ServeRestricted serves players with Geo restrictions
+	ServeRestricted bool
+	// CacheTTL of resolved players, five minutes by default
+	CacheTTL time.Duration
+	// NegativeCacheTTL of missing players, 30 seconds by default
+	NegativeCacheTTL time.Duration
+	// CacheSize limits number of cached players, 1024 by default.
+	// Expired entries are swept when the cache is full, then the oldest one is evicted.
+	CacheSize int
+
+	mu    sync.Mutex
+	cache map[string]*oEmbedEntry
+}
+
+// oEmbedEntry is a cached player
+type oEmbedEntry struct {
+	player *Player
+	object *Object
+	// poster is screenshot Object of the player when it's known
+	poster *Object
+	// err is set for missing player
+	err     error
+	added   time.Time
+	expires time.Time
+}
+
+// NewOEmbedProvider returns OEmbedProvider of players, objects may be nil
+func NewOEmbedProvider(players PlayersService, objects ObjectsService) *OEmbedProvider {
+	return &OEmbedProvider{
+		Players: players,
+		Objects: objects,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (p *OEmbedProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "xml" {
+		http.Error(w, "format not implemented", http.StatusNotImplemented)
+		return
+	}
+
+	maxWidth, err := oEmbedDimension(q.Get("maxwidth"))
+	if err != nil {
+		http.Error(w, "bad maxwidth", http.StatusBadRequest)
+		return
+	}
+
+	maxHeight, err := oEmbedDimension(q.Get("maxheight"))
+	if err != nil {
+		http.Error(w, "bad maxheight", http.StatusBadRequest)
+		return
+	}
+
+	id, ok := p.playerID(q.Get("url"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	entry, err := p.resolve(r.Context(), id)
+	if err != nil {
+		if notFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.Error(w, "can't resolve player", http.StatusBadGateway)
+		return
+	}
+
+	if entry.object != nil && entry.object.Private {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if !p.ServeRestricted && !permissiveGeo(entry.player.Geo) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	o := oEmbedOf(entry.player, entry.object, entry.poster, maxWidth, maxHeight)
+	o.ProviderURL = p.ProviderURL
+
+	if format == "xml" {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).Encode(o)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(o)
+}
+
+// playerID returns ID of player URL
+func (p *OEmbedProvider) playerID(s string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+
+	u, err := url.Parse(absoluteURL(s, ""))
+	if err != nil {
+		return "", false
+	}
+
+	if len(p.Hosts) > 0 {
+		allowed := false
+		for _, host := range p.Hosts {
+			if strings.EqualFold(u.Hostname(), host) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return "", false
+		}
+	}
+
+	id := path.Base(u.Path)
+	return id, idSegment.MatchString(id)
+}
+
+// resolve returns cached player and its source object, missing players are cached too
+func (p *OEmbedProvider) resolve(ctx context.Context, id string) (*oEmbedEntry, error) {
+	now := time.Now()
+
+	p.mu.Lock()
+	entry, ok := p.cache[id]
+	p.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry, entry.err
+	}
+
+	entry, err := p.fetch(ctx, id)
+	if err != nil && !notFound(err) {
+		return nil, err
+	}
+
+	ttl := p.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultOEmbedCacheTTL
+	}
+
+	if err != nil {
+		ttl = p.NegativeCacheTTL
+		if ttl <= 0 {
+			ttl = defaultOEmbedNegativeCacheTTL
+		}
+		entry = &oEmbedEntry{err: err}
+	}
+
+	entry.added = now
+	entry.expires = now.Add(ttl)
+	p.store(id, entry)
+
+	return entry, entry.err
+}
+
+// fetch returns player and its source object
+func (p *OEmbedProvider) fetch(ctx context.Context, id string) (*oEmbedEntry, error) {
+	player, _, err := p.Players.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := p.source(ctx, player)
+	if err != nil {
+		return nil, err
+	}
+
+	poster, err := p.poster(ctx, player)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oEmbedEntry{player: player, object: object, poster: poster}, nil
+}
+
+// store caches entry of id keeping the cache within CacheSize
+func (p *OEmbedProvider) store(id string, entry *oEmbedEntry) {
+	size := p.CacheSize
+	if size <= 0 {
+		size = defaultOEmbedCacheSize
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cache == nil {
+		p.cache = make(map[string]*oEmbedEntry)
+	}
+
+	if _, ok := p.cache[id]; !ok && len(p.cache) >= size {
+		for key, e := range p.cache {
+			if !entry.added.Before(e.expires) {
+				delete(p.cache, key)
+			}
+		}
+
+		for len(p.cache) >= size {
+			oldest := ""
+			for key, e := range p.cache {
+				if oldest == "" || e.added.Before(p.cache[oldest].added) {
+					oldest = key
+				}
+			}
+			delete(p.cache, oldest)
+		}
+	}
+
+	p.cache[id] = entry
+}
+
+// notFound reports whether err is API response of 404 Not Found
+func notFound(err error) bool {
+	errorResponse, ok := err.(*ErrorResponse)
+	return ok && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusNotFound
+}
+
+// source returns Object of the best quality video of player, nil when it can't be found
+func (p *OEmbedProvider) source(ctx context.Context, player *Player) (*Object, error) {
+	if p.Objects == nil {
+		return nil, nil
+	}
+
+	video := bestVideo(player.Videos)
+	if video == "" {
+		return nil, nil
+	}
+
+	if idSegment.MatchString(video) {
+		object, _, err := p.Objects.Get(ctx, video)
+		return object, err
+	}
+
+	params := &ObjectsListParams{Name: path.Base(video)}
+	objects, _, err := p.Objects.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range objects {
+		if objects[i].CDNURL != "" && stripScheme(objects[i].CDNURL) == stripScheme(video) {
+			return &objects[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// poster returns screenshot Object of player, nil when it isn't an Object
+func (p *OEmbedProvider) poster(ctx context.Context, player *Player) (*Object, error) {
+	if p.Objects == nil {
+		return nil, nil
+	}
+
+	id := previewID([]string{player.ScreenShotURL})
+	if id == "" {
+		return nil, nil
+	}
+
+	object, _, err := p.Objects.Get(ctx, id)
+	if notFound(err) {
+		return nil, nil
+	}
+
+	return object, err
+}
+
+// bestVideo returns video of the highest quality
+func bestVideo(videos videos) string {
+	best, video := -1, ""
+	for quality, v := range videos {
+		q, err := strconv.Atoi(strings.TrimSuffix(quality, "p"))
+		if err != nil {
+			q = 0
+		}
+
+		if q > best || (q == best && v < video) {
+			best, video = q, v
+		}
+	}
+
+	return video
+}
+
+// stripScheme returns URL without scheme
+func stripScheme(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		return s[i+3:]
+	}
+
+	return s
+}
+
+// oEmbedOf returns oEmbed response fitting into maxWidth and maxHeight, zero is unlimited
+func oEmbedOf(player *Player, object, poster *Object, maxWidth, maxHeight int) *OEmbed {
+	embed := NewEmbed(player, object)
+	if stream := objectVideoStream(poster); stream != nil {
+		embed.PosterWidth, embed.PosterHeight = int(stream.Width), int(stream.Height)
+	}
+
+	width, height := embed.Size()
+	ratio := float64(width) / float64(height)
+
+	if maxWidth > 0 && width > maxWidth {
+		width, height = maxWidth, int(float64(maxWidth)/ratio+0.5)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		width, height = int(float64(maxHeight)*ratio+0.5), maxHeight
+	}
+	embed.Width, embed.Height = width, height
+
+	o := embed.OEmbed()
+	if player.FrameTag != "" {
+		o.HTML = frameTag(player.FrameTag, width, height)
+	}
+
+	return o
+}
+
+// frameTag returns FrameTag of Player with absolute src and given dimensions
+func frameTag(tag string, width, height int) string {
+	tag = frameTagSrc.ReplaceAllStringFunc(tag, func(src string) string {
+		m := frameTagSrc.FindStringSubmatch(src)
+		return `src="` + absoluteURL(m[1], "") + `"`
+	})
+
+	tag = frameTagSize.ReplaceAllString(tag, "")
+
+	return frameTagStart.ReplaceAllString(tag, `<iframe width="`+strconv.Itoa(width)+`" height="`+strconv.Itoa(height)+`"`)
+}
+
+// oEmbedDimension parses maxwidth or maxheight param
+func oEmbedDimension(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, strconv.ErrSyntax
+	}
+
+	return n, nil
+}
@@ -0,0 +1,288 @@
+package filespot
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func handleOEmbedPlayers(t *testing.T, calls *int) {
+	mux.HandleFunc("/1/players/567d3643534b4474087c221e", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "player": {
+                "id": "567d3643534b4474087c221e",
+                "name": "player_name_1",
+                "videos": {
+                    "360": "cdn.platformcraft.ru/alex/example_360.mp4",
+                    "720": "cdn.platformcraft.ru/alex/example_720.mp4"
+                },
+                "screen_shot_url": "cdn.platformcraft.ru/alex/example.jpg",
+                "href": "video.platformcraft.ru/embed/567d3643534b4474087c221e",
+                "frame_tag": "<iframe width=\"558\" height=\"264\" src=\"video.platformcraft.ru/embed/567d3643534b4474087c221e\" frameBorder=\"0\" scrolling=\"no\" allowFullScreen></iframe>",
+                "geo": null
+            }
+        }`)
+	})
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c221f", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "player": {
+                "id": "567d3643534b4474087c221f",
+                "name": "player_name_2",
+                "href": "video.platformcraft.ru/embed/567d3643534b4474087c221f",
+                "geo": {"EU": {"RU": true}}
+            }
+        }`)
+	})
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c2210", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code": 404, "status": "error"}`)
+	})
+
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		if name := r.URL.Query().Get("name"); name != "example_720.mp4" {
+			t.Errorf("Objects.List name = %v, expected %v", name, "example_720.mp4")
+		}
+
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "objects": [
+                {
+                    "id": "56787f0c044dfe226b000001",
+                    "name": "example_720.mp4",
+                    "cdn_url": "cdn.platformcraft.ru/alex/example_720.mp4",
+                    "advanced": {
+                        "video_streams": [{"width": 1280, "height": 720, "display_aspect_ratio": "16:9"}]
+                    }
+                }
+            ]
+        }`)
+	})
+}
+
+func oEmbedRequest(h http.Handler, player, params string) *httptest.ResponseRecorder {
+	target := "/oembed?url=" + url.QueryEscape("https://video.platformcraft.ru/embed/"+player) + params
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestOEmbedProviderJSON(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleOEmbedPlayers(t, &calls)
+
+	provider := NewOEmbedProvider(client.Players, client.Objects)
+
+	w := oEmbedRequest(provider, "567d3643534b4474087c221e", "&maxwidth=640")
+	if w.Code != http.StatusOK {
+		t.Fatalf("OEmbedProvider status = %v, expected %v", w.Code, http.StatusOK)
+	}
+
+	o := new(OEmbed)
+	err := json.NewDecoder(w.Body).Decode(o)
+	if err != nil {
+		t.Fatalf("json.Decode returned error: %v", err)
+	}
+
+	expected := &OEmbed{
+		Type:            "video",
+		Version:         "1.0",
+		Title:           "player_name_1",
+		ProviderName:    "platformcraft",
+		ThumbnailURL:    "https://cdn.platformcraft.ru/alex/example.jpg",
+		ThumbnailWidth:  1280,
+		ThumbnailHeight: 720,
+		HTML:            `<iframe width="640" height="360" src="https://video.platformcraft.ru/embed/567d3643534b4474087c221e" frameBorder="0" scrolling="no" allowFullScreen></iframe>`,
+		Width:           640,
+		Height:          360,
+	}
+
+	if *o != *expected {
+		t.Errorf("OEmbedProvider = %+v, expected %+v", o, expected)
+	}
+
+	oEmbedRequest(provider, "567d3643534b4474087c221e", "")
+	if calls != 1 {
+		t.Errorf("Players.Get calls = %v, expected %v", calls, 1)
+	}
+}
+
+func TestOEmbedProviderXML(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleOEmbedPlayers(t, &calls)
+
+	provider := NewOEmbedProvider(client.Players, client.Objects)
+
+	w := oEmbedRequest(provider, "567d3643534b4474087c221e", "&format=xml&maxheight=480")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/xml") {
+		t.Errorf("OEmbedProvider Content-Type = %v, expected text/xml", ct)
+	}
+
+	o := new(OEmbed)
+	err := xml.NewDecoder(w.Body).Decode(o)
+	if err != nil {
+		t.Fatalf("xml.Decode returned error: %v", err)
+	}
+
+	if o.Width != 853 || o.Height != 480 {
+		t.Errorf("OEmbedProvider size = %vx%v, expected 853x480", o.Width, o.Height)
+	}
+}
+
+func TestOEmbedProviderPoster(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c221a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "player": {
+                "id": "567d3643534b4474087c221a",
+                "name": "player_name_3",
+                "videos": {"720": "56787f0c044dfe226b000001"},
+                "screen_shot_url": "cdn.platformcraft.ru/alex/56787f0c044dfe226b000002.jpg",
+                "href": "video.platformcraft.ru/embed/567d3643534b4474087c221a"
+            }
+        }`)
+	})
+
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000001", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "object": {
+                "id": "56787f0c044dfe226b000001",
+                "advanced": {"video_streams": [{"width": 1280, "height": 720, "display_aspect_ratio": "16:9"}]}
+            }
+        }`)
+	})
+
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000002", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+            "code": 200,
+            "status": "success",
+            "object": {
+                "id": "56787f0c044dfe226b000002",
+                "advanced": {"video_streams": [{"width": 640, "height": 360}]}
+            }
+        }`)
+	})
+
+	provider := NewOEmbedProvider(client.Players, client.Objects)
+
+	o := new(OEmbed)
+	w := oEmbedRequest(provider, "567d3643534b4474087c221a", "")
+	err := json.NewDecoder(w.Body).Decode(o)
+	if err != nil {
+		t.Fatalf("json.Decode returned error: %v", err)
+	}
+
+	if o.ThumbnailURL != "https://cdn.platformcraft.ru/alex/56787f0c044dfe226b000002.jpg" || o.ThumbnailWidth != 640 || o.ThumbnailHeight != 360 {
+		t.Errorf("OEmbedProvider thumbnail = %v %vx%v, expected screenshot of 640x360", o.ThumbnailURL, o.ThumbnailWidth, o.ThumbnailHeight)
+	}
+}
+
+func TestOEmbedProviderErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleOEmbedPlayers(t, &calls)
+
+	provider := NewOEmbedProvider(client.Players, nil)
+	provider.Hosts = []string{"video.platformcraft.ru"}
+
+	tests := []struct {
+		player string
+		params string
+		status int
+	}{
+		{"567d3643534b4474087c221f", "", http.StatusForbidden},
+		{"567d3643534b4474087c2210", "", http.StatusNotFound},
+		{"not-a-player", "", http.StatusNotFound},
+		{"567d3643534b4474087c221e", "&format=yaml", http.StatusNotImplemented},
+		{"567d3643534b4474087c221e", "&maxwidth=wide", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := oEmbedRequest(provider, tt.player, tt.params)
+		if w.Code != tt.status {
+			t.Errorf("OEmbedProvider(%v%v) status = %v, expected %v", tt.player, tt.params, w.Code, tt.status)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	provider.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oembed?url="+url.QueryEscape("https://example.com/embed/567d3643534b4474087c221e"), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("OEmbedProvider foreign host status = %v, expected %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestOEmbedProviderCache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleOEmbedPlayers(t, &calls)
+
+	missing := 0
+	mux.HandleFunc("/1/players/567d3643534b4474087c2211", func(w http.ResponseWriter, r *http.Request) {
+		missing++
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code": 404, "status": "error"}`)
+	})
+
+	provider := NewOEmbedProvider(client.Players, nil)
+	provider.CacheSize = 1
+
+	for _, player := range []string{"567d3643534b4474087c221e", "567d3643534b4474087c221e", "567d3643534b4474087c2211", "567d3643534b4474087c2211"} {
+		oEmbedRequest(provider, player, "")
+	}
+
+	if calls != 1 || missing != 1 {
+		t.Errorf("Players.Get calls = %v and %v, expected player and 404 cached", calls, missing)
+	}
+
+	w := oEmbedRequest(provider, "567d3643534b4474087c221e", "")
+	if w.Code != http.StatusOK || calls != 2 || len(provider.cache) != 1 {
+		t.Errorf("OEmbedProvider status = %v with %v calls and %v cached, expected evicted player fetched again", w.Code, calls, len(provider.cache))
+	}
+}
+
+func TestOEmbedProviderPrivate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c221e", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "player": {"id": "567d3643534b4474087c221e", "videos": {"720": "56787f0c044dfe226b000001"}}}`)
+	})
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000001", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "object": {"id": "56787f0c044dfe226b000001", "private": true}}`)
+	})
+
+	provider := NewOEmbedProvider(client.Players, client.Objects)
+
+	w := oEmbedRequest(provider, "567d3643534b4474087c221e", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("OEmbedProvider status = %v, expected %v", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -10,7 +10,7 @@ const playersBasePath = "/1/players"
 // PlayersService implements interface with API /players endpoint.
 // See https://doc.platformcraft.ru/filespot/api/en/#players
 type PlayersService interface {
-	List(context.Context, interface{}) ([]Player, *http.Response, error)
+	List(context.Context, *PlayersListParams) ([]Player, *http.Response, error)
 	Get(context.Context, string) (*Player, *http.Response, error)
 	Create(context.Context, *PlayerCreateRequest) (*Player, *http.Response, error)
 	Update(context.Context, string, *PlayerUpdateRequest) (*http.Response, error)
@@ -73,13 +73,27 @@ type PlayerUpdateRequest struct {
 	Folder       string `json:"folder"`
 	Videos       videos `json:"videos"`
 	ScreenShotID string `json:"screen_shot_id"`
+	VastAdTagURL string `json:"vast_ad_tag_url"`
 	Description  string `json:"description"`
 	Tags         tags   `json:"tags"`
 	Geo          Geo    `json:"geo"`
 }
 
+// PlayersListParams identifies as query params of List request
+type PlayersListParams struct {
+	// Filters
+	Folder string   `url:"folder,omitempty"`
+	Name   string   `url:"name,omitempty"`
+	Tags   []string `url:"tags,omitempty,comma"`
+
+	// Pagination
+	Limit    int `url:"limit,omitempty"`
+	Start    int `url:"start,omitempty"`
+	Pagingts int `url:"pagingts,omitempty"`
+}
+
 // List of Players
-func (c PlayersCli) List(ctx context.Context, params interface{}) ([]Player, *http.Response, error) {
+func (c PlayersCli) List(ctx context.Context, params *PlayersListParams) ([]Player, *http.Response, error) {
 	path, err := addParams(playersBasePath, params)
 	if err != nil {
 		return nil, nil, err
@@ -119,6 +133,11 @@ func (c PlayersCli) Get(ctx context.Context, id string) (*Player, *http.Response
 
 // Create Player
 func (c PlayersCli) Create(ctx context.Context, playerCreateRequest *PlayerCreateRequest) (*Player, *http.Response, error) {
+	err := validateGeo(playerCreateRequest.Geo)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req, err := c.client.NewRequest(ctx, http.MethodPost, playersBasePath, playerCreateRequest)
 	if err != nil {
 		return nil, nil, err
@@ -135,6 +154,11 @@ func (c PlayersCli) Create(ctx context.Context, playerCreateRequest *PlayerCreat
 
 // Update Player
 func (c PlayersCli) Update(ctx context.Context, id string, playerUpdateRequest *PlayerUpdateRequest) (*http.Response, error) {
+	err := validateGeo(playerUpdateRequest.Geo)
+	if err != nil {
+		return nil, err
+	}
+
 	endpointURL := playersBasePath + "/" + id
 
 	req, err := c.client.NewRequest(ctx, http.MethodPut, endpointURL, playerUpdateRequest)
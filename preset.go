@@ -0,0 +1,448 @@
+package filespot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultPresetCacheTTL = time.Hour
+
+// ErrPresetNotFound is returned by PresetCatalog when no preset matches
+var ErrPresetNotFound = errors.New("filespot: preset not found")
+
+// VideoSettings of Preset. Bit rate is in kbit/s.
+// Keys unknown to the struct are kept in Extra and encoded back as is,
+// known keys are encoded back as decoded while their fields are unchanged.
+type VideoSettings struct {
+	Codec     string
+	BitRate   int
+	FPS       float64
+	MaxWidth  int
+	MaxHeight int
+	Profile   string
+	Extra     map[string]string
+
+	// raw values of known keys which formatting of fields doesn't reproduce
+	raw map[string]string
+}
+
+// AudioSettings of Preset. Bit rate is in kbit/s.
+// Keys unknown to the struct are kept in Extra and encoded back as is,
+// known keys are encoded back as decoded while their fields are unchanged.
+type AudioSettings struct {
+	Codec      string
+	BitRate    int
+	SampleRate int
+	Channels   int
+	Extra      map[string]string
+
+	// raw values of known keys which formatting of fields doesn't reproduce
+	raw map[string]string
+}
+
+// WatermarkSlot is a place for watermark defined by Preset.
+// Offsets and sizes are kept as API returns them, e.g. "10%".
+// Keys unknown to the struct are kept in Extra and encoded back as is,
+// known keys are encoded back as decoded while their fields are unchanged.
+type WatermarkSlot struct {
+	HorizontalAlign  string
+	HorizontalOffset string
+	VerticalAlign    string
+	VerticalOffset   string
+	MaxWidth         string
+	MaxHeight        string
+	Opacity          int
+	SizingPolicy     string
+	Extra            map[string]string
+
+	// raw values of known keys which formatting of fields doesn't reproduce
+	raw map[string]string
+}
+
+// PresetFilter selects presets in PresetCatalog.Find, zero fields match any preset
+type PresetFilter struct {
+	// Name is matched case-insensitively as substring
+	Name      string
+	Container string
+	// Width and Height are matched with maximal video size of preset
+	Width  int
+	Height int
+}
+
+// PresetCatalog caches TranscoderService.Presets and looks presets up.
+// PresetCatalog is safe for concurrent use.
+type PresetCatalog struct {
+	Transcoder TranscoderService
+	// TTL of cached presets, one hour by default
+	TTL time.Duration
+
+	mu      sync.Mutex
+	presets []Preset
+	fetched time.Time
+}
+
+// NewPresetCatalog returns PresetCatalog of transcoder
+func NewPresetCatalog(transcoder TranscoderService) *PresetCatalog {
+	return &PresetCatalog{
+		Transcoder: transcoder,
+	}
+}
+
+// Presets returns copies of cached presets fetching them when cache is stale
+func (c *PresetCatalog) Presets(ctx context.Context) ([]Preset, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = defaultPresetCacheTTL
+	}
+
+	if c.presets != nil && time.Since(c.fetched) < ttl {
+		return clonePresets(c.presets), nil
+	}
+
+	presets, _, err := c.Transcoder.Presets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.presets = presets
+	c.fetched = time.Now()
+
+	return clonePresets(presets), nil
+}
+
+// Invalidate drops cached presets
+func (c *PresetCatalog) Invalidate() {
+	c.mu.Lock()
+	c.presets = nil
+	c.mu.Unlock()
+}
+
+// ByID returns preset of id
+func (c *PresetCatalog) ByID(ctx context.Context, id string) (*Preset, error) {
+	presets, err := c.Presets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range presets {
+		if presets[i].ID == id {
+			return &presets[i], nil
+		}
+	}
+
+	return nil, ErrPresetNotFound
+}
+
+// ByName returns preset of name, case is ignored
+func (c *PresetCatalog) ByName(ctx context.Context, name string) (*Preset, error) {
+	presets, err := c.Presets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range presets {
+		if strings.EqualFold(presets[i].Name, name) {
+			return &presets[i], nil
+		}
+	}
+
+	return nil, ErrPresetNotFound
+}
+
+// Find returns presets matching filter ordered by descending video height
+func (c *PresetCatalog) Find(ctx context.Context, filter *PresetFilter) ([]Preset, error) {
+	presets, err := c.Presets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter == nil {
+		filter = new(PresetFilter)
+	}
+
+	var found []Preset
+	for _, preset := range presets {
+		if filter.matches(&preset) {
+			found = append(found, preset)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].height() > found[j].height()
+	})
+
+	return found, nil
+}
+
+// matches reports whether preset matches filter
+func (f *PresetFilter) matches(preset *Preset) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(preset.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+
+	if f.Container != "" && !strings.EqualFold(preset.Container, f.Container) {
+		return false
+	}
+
+	if f.Width > 0 && (preset.Video == nil || preset.Video.MaxWidth != f.Width) {
+		return false
+	}
+
+	if f.Height > 0 && (preset.Video == nil || preset.Video.MaxHeight != f.Height) {
+		return false
+	}
+
+	return true
+}
+
+// height returns maximal video height of preset
+func (p *Preset) height() int {
+	if p.Video == nil {
+		return 0
+	}
+
+	return p.Video.MaxHeight
+}
+
+// clonePresets returns deep copy of presets
+func clonePresets(presets []Preset) []Preset {
+	clones := make([]Preset, len(presets))
+	for i := range presets {
+		clones[i] = presets[i].clone()
+	}
+
+	return clones
+}
+
+// clone returns deep copy of preset
+func (p *Preset) clone() Preset {
+	c := *p
+
+	if p.Video != nil {
+		video := *p.Video
+		video.Extra, video.raw = cloneStrings(video.Extra), cloneStrings(video.raw)
+		c.Video = &video
+	}
+
+	if p.Audio != nil {
+		audio := *p.Audio
+		audio.Extra, audio.raw = cloneStrings(audio.Extra), cloneStrings(audio.raw)
+		c.Audio = &audio
+	}
+
+	if p.Watermarks != nil {
+		c.Watermarks = make(map[string]WatermarkSlot, len(p.Watermarks))
+		for position, slot := range p.Watermarks {
+			slot.Extra, slot.raw = cloneStrings(slot.Extra), cloneStrings(slot.raw)
+			c.Watermarks[position] = slot
+		}
+	}
+
+	return c
+}
+
+// cloneStrings returns copy of m, nil is kept
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+
+	return c
+}
+
+// settings maps API keys to fields of settings struct,
+// fields are *string, *int or *float64
+type settings map[string]interface{}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *VideoSettings) UnmarshalJSON(b []byte) error {
+	extra, raw, err := v.settings().decode(b)
+	v.Extra, v.raw = extra, raw
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (v VideoSettings) MarshalJSON() ([]byte, error) {
+	return v.settings().encode(v.Extra, v.raw)
+}
+
+// settings returns fields of VideoSettings
+func (v *VideoSettings) settings() settings {
+	return settings{
+		"codec":      &v.Codec,
+		"bit_rate":   &v.BitRate,
+		"fps":        &v.FPS,
+		"max_width":  &v.MaxWidth,
+		"max_height": &v.MaxHeight,
+		"profile":    &v.Profile,
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *AudioSettings) UnmarshalJSON(b []byte) error {
+	extra, raw, err := a.settings().decode(b)
+	a.Extra, a.raw = extra, raw
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (a AudioSettings) MarshalJSON() ([]byte, error) {
+	return a.settings().encode(a.Extra, a.raw)
+}
+
+// settings returns fields of AudioSettings
+func (a *AudioSettings) settings() settings {
+	return settings{
+		"codec":       &a.Codec,
+		"bit_rate":    &a.BitRate,
+		"sample_rate": &a.SampleRate,
+		"channels":    &a.Channels,
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (w *WatermarkSlot) UnmarshalJSON(b []byte) error {
+	extra, raw, err := w.settings().decode(b)
+	w.Extra, w.raw = extra, raw
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (w WatermarkSlot) MarshalJSON() ([]byte, error) {
+	return w.settings().encode(w.Extra, w.raw)
+}
+
+// settings returns fields of WatermarkSlot
+func (w *WatermarkSlot) settings() settings {
+	return settings{
+		"horizontal_align":  &w.HorizontalAlign,
+		"horizontal_offset": &w.HorizontalOffset,
+		"vertical_align":    &w.VerticalAlign,
+		"vertical_offset":   &w.VerticalOffset,
+		"max_width":         &w.MaxWidth,
+		"max_height":        &w.MaxHeight,
+		"opacity":           &w.Opacity,
+		"sizing_policy":     &w.SizingPolicy,
+	}
+}
+
+// decode sets fields from JSON object of strings and returns unknown keys
+// and raw values of known keys which format doesn't reproduce, e.g. "29.970" or "0".
+// Values which can't be parsed are returned as unknown ones to keep them.
+func (s settings) decode(b []byte) (extra, raw map[string]string, err error) {
+	values := make(map[string]string)
+	err = json.Unmarshal(b, &values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key, value := range values {
+		if !s.set(key, value) {
+			if extra == nil {
+				extra = make(map[string]string)
+			}
+			extra[key] = value
+			continue
+		}
+
+		if formatted, ok := s.format(key); !ok || formatted != value {
+			if raw == nil {
+				raw = make(map[string]string)
+			}
+			raw[key] = value
+		}
+	}
+
+	return extra, raw, nil
+}
+
+// set parses value into field of key, it reports whether it succeeded
+func (s settings) set(key, value string) bool {
+	switch field := s[key].(type) {
+	case *string:
+		*field = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return false
+		}
+		*field = n
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		*field = f
+	default:
+		return false
+	}
+
+	return true
+}
+
+// format returns value of field of key, ok is false when the field is zero
+func (s settings) format(key string) (value string, ok bool) {
+	switch field := s[key].(type) {
+	case *string:
+		return *field, *field != ""
+	case *int:
+		return strconv.Itoa(*field), *field != 0
+	case *float64:
+		return strconv.FormatFloat(*field, 'f', -1, 64), *field != 0
+	}
+
+	return "", false
+}
+
+// unchanged reports whether field of key still holds value
+func (s settings) unchanged(key, value string) bool {
+	switch field := s[key].(type) {
+	case *string:
+		return *field == value
+	case *int:
+		n, err := strconv.Atoi(value)
+		return err == nil && n == *field
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		return err == nil && f == *field
+	}
+
+	return false
+}
+
+// encode returns JSON object of extra keys and fields, fields are omitted
+// when they are zero and weren't decoded
+func (s settings) encode(extra, raw map[string]string) ([]byte, error) {
+	values := make(map[string]string, len(s)+len(extra))
+	for key, value := range extra {
+		values[key] = value
+	}
+
+	for key := range s {
+		value, ok := s.format(key)
+		decoded, present := raw[key]
+
+		switch {
+		case present && s.unchanged(key, decoded):
+			values[key] = decoded
+		case ok || present:
+			values[key] = value
+		}
+	}
+
+	return json.Marshal(values)
+}
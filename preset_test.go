@@ -0,0 +1,159 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPresetSettingsRoundTrip(t *testing.T) {
+	in := `{"audio":{"bit_rate":"160","channels":"2","codec":"AAC","sample_rate":"44100"},` +
+		`"container":"mp4","id":"566b0fbf044dfe64f2000002","name":"Generic",` +
+		`"video":{"bit_rate":"auto","codec":"H.264","fps":"29.97","keyframes":"60","max_height":"1080","max_width":"1920","profile":"high"},` +
+		`"watermarks":{"Full":{"opacity":"100","rotation":"90","sizing_policy":"Fit"}}}`
+
+	preset := new(Preset)
+	err := json.Unmarshal([]byte(in), preset)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	expected := &VideoSettings{
+		Codec:     "H.264",
+		FPS:       29.97,
+		MaxWidth:  1920,
+		MaxHeight: 1080,
+		Profile:   "high",
+		Extra:     map[string]string{"bit_rate": "auto", "keyframes": "60"},
+	}
+
+	if !reflect.DeepEqual(preset.Video, expected) {
+		t.Errorf("Preset.Video = %+v, expected %+v", preset.Video, expected)
+	}
+
+	if slot := preset.Watermarks["Full"]; slot.Opacity != 100 || slot.Extra["rotation"] != "90" {
+		t.Errorf("Preset.Watermarks = %+v, expected opacity 100 and rotation 90", slot)
+	}
+
+	out, err := json.Marshal(preset)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	var a, b map[string]interface{}
+	json.Unmarshal([]byte(in), &a)
+	json.Unmarshal(out, &b)
+
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Preset round trip = %s, expected %s", out, in)
+	}
+}
+
+func TestPresetSettingsKnownKeys(t *testing.T) {
+	in := `{"bit_rate":"0","fps":"29.970","profile":""}`
+
+	video := new(VideoSettings)
+	err := json.Unmarshal([]byte(in), video)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	out, _ := json.Marshal(video)
+	if string(out) != in {
+		t.Errorf("VideoSettings round trip = %s, expected %s", out, in)
+	}
+
+	video.FPS = 25
+	video.Codec = "H.264"
+	out, _ = json.Marshal(video)
+	expected := `{"bit_rate":"0","codec":"H.264","fps":"25","profile":""}`
+	if string(out) != expected {
+		t.Errorf("json.Marshal of changed VideoSettings = %s, expected %s", out, expected)
+	}
+}
+
+func TestPresetCatalog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{
+            "code": 200,
+            "presets": [
+                {"id": "566b0fbf044dfe64f2000003", "name": "System preset: Generic 720p", "container": "mp4", "video": {"max_width": "1280", "max_height": "720"}},
+                {"id": "566b0fbf044dfe64f2000002", "name": "System preset: Generic 1080p", "container": "mp4", "video": {"max_width": "1920", "max_height": "1080"}},
+                {"id": "566b0fbf044dfe64f2000005", "name": "System preset: Webm 720p", "container": "webm", "video": {"max_width": "1280", "max_height": "720"}},
+                {"id": "566b0fbf044dfe64f2000006", "name": "System preset: Audio only", "container": "mp3", "video": null}
+            ]
+        }`)
+	})
+
+	catalog := NewPresetCatalog(client.Transcoder)
+
+	preset, err := catalog.ByName(ctx, "system preset: generic 1080p")
+	if err != nil || preset.ID != "566b0fbf044dfe64f2000002" {
+		t.Errorf("PresetCatalog.ByName = %v, %v, expected %v", preset, err, "566b0fbf044dfe64f2000002")
+	}
+
+	_, err = catalog.ByName(ctx, "missing")
+	if err != ErrPresetNotFound {
+		t.Errorf("PresetCatalog.ByName returned error: %v, expected %v", err, ErrPresetNotFound)
+	}
+
+	preset, err = catalog.ByID(ctx, "566b0fbf044dfe64f2000005")
+	if err != nil || preset.Container != "webm" {
+		t.Errorf("PresetCatalog.ByID = %v, %v, expected webm preset", preset, err)
+	}
+
+	// changes of returned presets don't reach the cache
+	preset.Video.MaxHeight = 2160
+	presets, _ := catalog.Presets(ctx)
+	presets[0].Name = "changed"
+
+	preset, _ = catalog.ByID(ctx, "566b0fbf044dfe64f2000005")
+	presets, _ = catalog.Presets(ctx)
+	if preset.Video.MaxHeight != 720 || presets[0].Name != "System preset: Generic 720p" {
+		t.Errorf("PresetCatalog presets = %v, %v, expected cache unchanged", preset.Video, presets[0].Name)
+	}
+
+	tests := []struct {
+		filter   *PresetFilter
+		expected []string
+	}{
+		{nil, []string{"566b0fbf044dfe64f2000002", "566b0fbf044dfe64f2000003", "566b0fbf044dfe64f2000005", "566b0fbf044dfe64f2000006"}},
+		{&PresetFilter{Container: "MP4"}, []string{"566b0fbf044dfe64f2000002", "566b0fbf044dfe64f2000003"}},
+		{&PresetFilter{Height: 720}, []string{"566b0fbf044dfe64f2000003", "566b0fbf044dfe64f2000005"}},
+		{&PresetFilter{Name: "webm", Width: 1280}, []string{"566b0fbf044dfe64f2000005"}},
+		{&PresetFilter{Height: 2160}, nil},
+	}
+
+	for _, tt := range tests {
+		presets, err := catalog.Find(ctx, tt.filter)
+		if err != nil {
+			t.Errorf("PresetCatalog.Find returned error: %v", err)
+		}
+
+		var ids []string
+		for _, preset := range presets {
+			ids = append(ids, preset.ID)
+		}
+
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("PresetCatalog.Find(%+v) = %v, expected %v", tt.filter, ids, tt.expected)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Transcoder.Presets calls = %v, expected %v", calls, 1)
+	}
+
+	catalog.Invalidate()
+	catalog.Presets(ctx)
+	if calls != 2 {
+		t.Errorf("Transcoder.Presets calls = %v after Invalidate, expected %v", calls, 2)
+	}
+}
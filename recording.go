@@ -0,0 +1,653 @@
+package filespot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const objectStatusOK = "ok"
+
+// Recording session states
+const (
+	RecordingIdle       = "idle"
+	RecordingActive     = "recording"
+	RecordingProcessing = "processing"
+	RecordingDone       = "done"
+	RecordingFailed     = "failed"
+)
+
+// Recording pipeline stages
+const (
+	StageSettle    = "settle"
+	StageTranscode = "transcode"
+	StageHLS       = "hls"
+	StagePlayer    = "player"
+	StageCompleted = "completed"
+)
+
+// recordingStages are pipeline stages in order
+var recordingStages = []string{StageSettle, StageTranscode, StageHLS, StagePlayer, StageCompleted}
+
+var (
+	// ErrRecordingActive is returned by RecordingSession.Start when recording is in progress
+	ErrRecordingActive = errors.New("filespot: recording is in progress")
+	// ErrRecordingInactive is returned by RecordingSession.Stop when nothing is recorded
+	ErrRecordingInactive = errors.New("filespot: recording is not in progress")
+	// ErrRecordingFiles is returned by player stage of recording of several files
+	ErrRecordingFiles = errors.New("filespot: player of several recorded files isn't supported")
+)
+
+// RecordingState is persisted state of RecordingSession
+type RecordingState struct {
+	StreamID  string    `json:"stream_id"`
+	State     string    `json:"state"`
+	Stage     string    `json:"stage,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	StoppedAt time.Time `json:"stopped_at,omitempty"`
+	// Deadline of recording enforced by StopTimeout
+	Deadline time.Time `json:"deadline,omitempty"`
+	RecordID string    `json:"record_id,omitempty"`
+	// Folder of transcoded files of the session
+	Folder string `json:"folder,omitempty"`
+	// Files are IDs of recorded objects
+	Files      []string `json:"files,omitempty"`
+	Renditions []string `json:"renditions,omitempty"`
+	PlayerID   string   `json:"player_id,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// RecordingEvent is emitted when post-processing of recording finishes
+type RecordingEvent struct {
+	State  RecordingState
+	Player *Player
+	Err    error
+}
+
+// SessionStore persists RecordingState
+type SessionStore interface {
+	Save(ctx context.Context, state *RecordingState) error
+	// Load returns nil state when nothing is saved
+	Load(ctx context.Context, streamID string) (*RecordingState, error)
+}
+
+// FileSessionStore saves RecordingState of every stream to JSON file in Dir
+type FileSessionStore struct {
+	Dir string
+}
+
+// RecordingPipeline configures post-processing of recorded files
+type RecordingPipeline struct {
+	// Presets of transcoding and HLS, transcoding is skipped when empty
+	Presets []string
+	// Path of transcoded files, "/recordings/<stream id>" by default.
+	// Every session uses its own subfolder named by record ID or start time.
+	Path string
+	// HLS builds HLS of recorded files with SegmentDuration
+	HLS             bool
+	SegmentDuration int
+	// Player creates player of transcoded renditions with PlayerOptions.
+	// Renditions of different files can't be told apart in the session folder,
+	// so recordings of several files fail with ErrRecordingFiles after transcoding,
+	// concat them with ConcatBuilder and create player of the result instead.
+	Player        bool
+	PlayerOptions *PlayerOptions
+	// Settled reports whether recorded object is ready for processing,
+	// status "ok" is awaited by default
+	Settled func(*Object) bool
+	// PollInterval of objects and tasks, five seconds by default
+	PollInterval time.Duration
+}
+
+// RecordingSession records stream and post-processes recorded files
+// with Pipeline. State is saved to Store after every step when set.
+type RecordingSession struct {
+	StreamID string
+	Client   *Client
+	// StopTimeout stops recording when it lasts longer, it's passed to StreamsService.Start too
+	StopTimeout time.Duration
+	Pipeline    *RecordingPipeline
+	Store       SessionStore
+	// OnComplete receives event when post-processing finishes
+	OnComplete func(RecordingEvent)
+
+	mu         sync.Mutex
+	state      RecordingState
+	timer      *time.Timer
+	processing bool
+	// switching is set while Start or Stop calls API
+	switching bool
+	// cancel stops post-processing started by StopTimeout
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRecordingSession returns RecordingSession of stream
+func NewRecordingSession(client *Client, streamID string, pipeline *RecordingPipeline) *RecordingSession {
+	return &RecordingSession{
+		StreamID: streamID,
+		Client:   client,
+		Pipeline: pipeline,
+		state: RecordingState{
+			StreamID: streamID,
+			State:    RecordingIdle,
+		},
+	}
+}
+
+// State returns copy of session state
+func (s *RecordingSession) State() RecordingState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state
+}
+
+// Restore loads saved state and resumes it: overdue recording is stopped
+// and interrupted post-processing is run again from its stage.
+func (s *RecordingSession) Restore(ctx context.Context) error {
+	if s.Store == nil {
+		return nil
+	}
+
+	state, err := s.Store.Load(ctx, s.StreamID)
+	if err != nil || state == nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.state = *state
+	s.mu.Unlock()
+
+	switch state.State {
+	case RecordingActive:
+		if state.Deadline.IsZero() {
+			return nil
+		}
+
+		remaining := time.Until(state.Deadline)
+		if remaining <= 0 {
+			return s.Stop(ctx)
+		}
+
+		s.mu.Lock()
+		s.armTimer(remaining)
+		s.mu.Unlock()
+	case RecordingProcessing:
+		return s.Process(ctx)
+	}
+
+	return nil
+}
+
+// Start starts recording
+func (s *RecordingSession) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.switching || s.state.State == RecordingActive || s.state.State == RecordingProcessing {
+		s.mu.Unlock()
+		return ErrRecordingActive
+	}
+	s.switching = true
+	s.mu.Unlock()
+
+	streamStartRequest := &StreamStartRequest{StopTimeout: int(s.StopTimeout / time.Second)}
+	_, err := s.Client.Streams.Start(ctx, s.StreamID, streamStartRequest)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.switching = false
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.state = RecordingState{
+		StreamID:  s.StreamID,
+		State:     RecordingActive,
+		StartedAt: now,
+	}
+
+	if s.StopTimeout > 0 {
+		s.state.Deadline = now.Add(s.StopTimeout)
+		s.armTimer(s.StopTimeout)
+	}
+
+	return s.save(ctx)
+}
+
+// Stop stops recording and post-processes recorded files with ctx before it returns
+func (s *RecordingSession) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.switching || s.state.State != RecordingActive {
+		s.mu.Unlock()
+		return ErrRecordingInactive
+	}
+	s.switching = true
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	files, _, err := s.Client.Streams.Stop(ctx, s.StreamID)
+
+	s.mu.Lock()
+	s.switching = false
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.state.State = RecordingProcessing
+	s.state.StoppedAt = time.Now()
+	s.state.Files = nil
+	for _, file := range files {
+		s.state.Files = append(s.state.Files, file.ID)
+	}
+	s.state.Folder = s.folder(&s.state)
+
+	err = s.save(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return s.Process(ctx)
+}
+
+// Rec adopts record scheduled on the server, it's post-processed once finished.
+// Call Rec again until the record has files.
+func (s *RecordingSession) Rec(ctx context.Context, recordID string) (*Record, error) {
+	record, _, err := s.Client.Streams.Rec(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.state.State == RecordingActive || s.state.State == RecordingProcessing {
+		s.mu.Unlock()
+		return record, ErrRecordingActive
+	}
+
+	s.state = RecordingState{
+		StreamID: s.StreamID,
+		State:    RecordingIdle,
+		RecordID: recordID,
+	}
+
+	if record.Status == RecordError {
+		s.mu.Unlock()
+		return record, ErrRecordFailed
+	}
+
+	finished := record.Status == RecordFinish && len(record.Files) > 0
+	if finished {
+		s.state.State = RecordingProcessing
+		s.state.Files = record.Files
+		s.state.Folder = s.folder(&s.state)
+	}
+
+	err = s.save(ctx)
+	s.mu.Unlock()
+	if err != nil || !finished {
+		return record, err
+	}
+
+	return record, s.Process(ctx)
+}
+
+// Process runs post-processing pipeline of recorded files from the saved stage
+func (s *RecordingSession) Process(ctx context.Context) error {
+	s.mu.Lock()
+	if s.state.State != RecordingProcessing || s.processing {
+		s.mu.Unlock()
+		return ErrRecordingInactive
+	}
+	s.processing = true
+	state := s.state
+	s.mu.Unlock()
+
+	player, err := s.process(ctx, &state)
+
+	if err != nil {
+		state.State = RecordingFailed
+		state.Error = err.Error()
+	} else {
+		state.State = RecordingDone
+		state.Stage = StageCompleted
+	}
+
+	if serr := s.commit(ctx, &state); serr != nil && err == nil {
+		err = serr
+	}
+
+	s.mu.Lock()
+	s.processing = false
+	s.mu.Unlock()
+
+	if s.OnComplete != nil {
+		s.OnComplete(RecordingEvent{State: state, Player: player, Err: err})
+	}
+
+	return err
+}
+
+// process runs pipeline stages skipping completed ones
+func (s *RecordingSession) process(ctx context.Context, state *RecordingState) (*Player, error) {
+	p := s.Pipeline
+	if p == nil {
+		p = new(RecordingPipeline)
+	}
+
+	if len(state.Files) == 0 {
+		return nil, errors.New("filespot: no recorded files")
+	}
+
+	sources := make([]*Object, 0, len(state.Files))
+	for _, id := range state.Files {
+		object, err := s.settle(ctx, p, id)
+		if err != nil {
+			return nil, fmt.Errorf("filespot: settle %v: %v", id, err)
+		}
+		sources = append(sources, object)
+	}
+
+	err := s.advance(ctx, state, StageSettle)
+	if err != nil || len(p.Presets) == 0 {
+		return nil, err
+	}
+
+	folder := state.Folder
+	if folder == "" {
+		folder = s.folder(state)
+		state.Folder = folder
+	}
+
+	if !stageDone(state.Stage, StageTranscode) {
+		for _, source := range sources {
+			transcoderCreateRequest := &TranscoderCreateRequest{
+				Presets: p.Presets,
+				Path:    folder,
+			}
+
+			err := s.runTask(ctx, p, func() (*Transcoder, error) {
+				transcoder, _, err := s.Client.Transcoder.Create(ctx, source.ID, transcoderCreateRequest)
+				return transcoder, err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("filespot: transcode %v: %v", source.ID, err)
+			}
+		}
+
+		err := s.advance(ctx, state, StageTranscode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.HLS && !stageDone(state.Stage, StageHLS) {
+		for _, source := range sources {
+			transcoderHLSRequest := &TranscoderHLSRequest{
+				Presets:         p.Presets,
+				SegmentDuration: p.SegmentDuration,
+			}
+
+			err := s.runTask(ctx, p, func() (*Transcoder, error) {
+				transcoder, _, err := s.Client.Transcoder.HLS(ctx, source.ID, transcoderHLSRequest)
+				return transcoder, err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("filespot: hls %v: %v", source.ID, err)
+			}
+		}
+
+		err := s.advance(ctx, state, StageHLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.Player {
+		return nil, nil
+	}
+
+	if len(sources) > 1 {
+		return nil, ErrRecordingFiles
+	}
+
+	renditions, err := s.renditions(ctx, folder, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Renditions = nil
+	for _, rendition := range renditions {
+		state.Renditions = append(state.Renditions, rendition.ID)
+	}
+
+	// EnsurePlayer is idempotent, so the stage is run again on resume
+	player, _, err := EnsurePlayer(ctx, s.Client.Players, sources[0], renditions, p.PlayerOptions)
+	if err != nil {
+		return nil, fmt.Errorf("filespot: player: %v", err)
+	}
+	state.PlayerID = player.ID
+
+	return player, s.advance(ctx, state, StagePlayer)
+}
+
+// advance marks stage completed and persists state
+func (s *RecordingSession) advance(ctx context.Context, state *RecordingState, stage string) error {
+	if stageDone(state.Stage, stage) {
+		return nil
+	}
+
+	state.Stage = stage
+	return s.commit(ctx, state)
+}
+
+// commit replaces session state and persists it
+func (s *RecordingSession) commit(ctx context.Context, state *RecordingState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = *state
+	return s.save(ctx)
+}
+
+// stageDone reports whether current stage is stage or a later one
+func stageDone(current, stage string) bool {
+	index := func(st string) int {
+		for i, v := range recordingStages {
+			if v == st {
+				return i
+			}
+		}
+		return -1
+	}
+
+	return current != "" && index(current) >= index(stage)
+}
+
+// settle waits until recorded object is ready
+func (s *RecordingSession) settle(ctx context.Context, p *RecordingPipeline, id string) (*Object, error) {
+	settled := p.Settled
+	if settled == nil {
+		settled = func(object *Object) bool {
+			return object.Status == objectStatusOK
+		}
+	}
+
+	ticker := time.NewTicker(p.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		object, _, err := s.Client.Objects.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if settled(object) {
+			return object, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// runTask creates transcoder task and waits for it
+func (s *RecordingSession) runTask(ctx context.Context, p *RecordingPipeline, create func() (*Transcoder, error)) error {
+	transcoder, err := create()
+	if err != nil {
+		return err
+	}
+
+	_, err = WaitTask(ctx, s.Client.TranscoderTasks, transcoder.TaskID, p.pollInterval())
+	return err
+}
+
+// renditions returns transcoded objects in folder
+func (s *RecordingSession) renditions(ctx context.Context, folder string, sources []*Object) ([]Object, error) {
+	objects, _, err := s.Client.Objects.List(ctx, &ObjectsListParams{Folder: folder})
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		skip[source.ID] = true
+	}
+
+	renditions := make([]Object, 0, len(objects))
+	for _, object := range objects {
+		if object.IsDir || skip[object.ID] {
+			continue
+		}
+
+		renditions = append(renditions, object)
+	}
+
+	return renditions, nil
+}
+
+// Close stops timer of StopTimeout and cancels post-processing started by it.
+// Recording itself isn't stopped.
+func (s *RecordingSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	if s.cancel != nil {
+		s.cancel()
+		s.ctx, s.cancel = nil, nil
+	}
+}
+
+// folder returns folder of transcoded files of session in state
+func (s *RecordingSession) folder(state *RecordingState) string {
+	base := "/recordings/" + s.StreamID
+	if s.Pipeline != nil && s.Pipeline.Path != "" {
+		base = s.Pipeline.Path
+	}
+
+	switch {
+	case state.RecordID != "":
+		return base + "/" + state.RecordID
+	case !state.StartedAt.IsZero():
+		return base + "/" + state.StartedAt.UTC().Format("20060102T150405Z")
+	case len(state.Files) > 0:
+		return base + "/" + state.Files[0]
+	}
+
+	return base
+}
+
+// armTimer stops recording after d, session must be locked
+func (s *RecordingSession) armTimer(d time.Duration) {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	ctx := s.ctx
+
+	s.timer = time.AfterFunc(d, func() {
+		err := s.Stop(ctx)
+		if err != nil && err != ErrRecordingInactive && s.OnComplete != nil {
+			s.OnComplete(RecordingEvent{State: s.State(), Err: err})
+		}
+	})
+}
+
+// save persists state, session must be locked
+func (s *RecordingSession) save(ctx context.Context) error {
+	if s.Store == nil {
+		return nil
+	}
+
+	state := s.state
+	return s.Store.Save(ctx, &state)
+}
+
+// pollInterval returns interval of polling
+func (p *RecordingPipeline) pollInterval() time.Duration {
+	if p.PollInterval <= 0 {
+		return defaultWaitInterval
+	}
+
+	return p.PollInterval
+}
+
+// Save writes state to file of its stream
+func (f *FileSessionStore) Save(ctx context.Context, state *RecordingState) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := f.path(state.StreamID)
+	tmp := name + ".tmp"
+
+	err = os.WriteFile(tmp, b, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
+
+// Load reads state of stream from file
+func (f *FileSessionStore) Load(ctx context.Context, streamID string) (*RecordingState, error) {
+	b, err := os.ReadFile(f.path(streamID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(RecordingState)
+	err = json.Unmarshal(b, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// path returns file of stream state
+func (f *FileSessionStore) path(streamID string) string {
+	return filepath.Join(f.Dir, "recording-"+filepath.Base(streamID)+".json")
+}
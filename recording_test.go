@@ -0,0 +1,319 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func handleRecordingPipeline(t *testing.T, calls *[]string) {
+	var mu sync.Mutex
+	record := func(call string) {
+		mu.Lock()
+		*calls = append(*calls, call)
+		mu.Unlock()
+	}
+
+	mux.HandleFunc("/1/streams/rec/instant/start/56cec7e2fa63afd0f843567d", func(w http.ResponseWriter, r *http.Request) {
+		v := new(StreamStartRequest)
+		json.NewDecoder(r.Body).Decode(v)
+		record(fmt.Sprintf("start %v", v.StopTimeout))
+		fmt.Fprint(w, `{"code": 200, "status": "success"}`)
+	})
+
+	mux.HandleFunc("/1/streams/rec/instant/stop/56cec7e2fa63afd0f843567d", func(w http.ResponseWriter, r *http.Request) {
+		record("stop")
+		fmt.Fprint(w, `{"code": 200, "status": "success", "files": [{"id": "5bd37808534b441c4acf7415", "name": "rec.mp4"}]}`)
+	})
+
+	polls := 0
+	mux.HandleFunc("/1/objects/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		status := "processing"
+		if polls++; polls > 1 {
+			status = "ok"
+		}
+		fmt.Fprintf(w, `{"code": 200, "status": "success", "object": {"id": "5bd37808534b441c4acf7415", "name": "rec.mp4", "status": %q}}`, status)
+	})
+
+	mux.HandleFunc("/1/transcoder/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TranscoderCreateRequest)
+		json.NewDecoder(r.Body).Decode(v)
+		record("transcode " + v.Path)
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0001"}`)
+	})
+
+	mux.HandleFunc("/1/transcoder/hls/5bd37808534b441c4acf7415", func(w http.ResponseWriter, r *http.Request) {
+		record("hls")
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0002"}`)
+	})
+
+	mux.HandleFunc("/1/transcoder_tasks/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task": {"id": "5bd37808534b441c4acf0001", "status": "Completed"}}`)
+	})
+
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "objects": [
+            {"id": "5bd37808534b441c4acf7415"},
+            {"id": "5bd37808534b441c4acf0360", "advanced": {"video_streams": [{"height": 360}]}},
+            {"id": "5bd37808534b441c4acf0720", "advanced": {"video_streams": [{"height": 720}]}}
+        ]}`)
+	})
+
+	mux.HandleFunc("/1/players", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"code": 200, "status": "success", "players": []}`)
+			return
+		}
+
+		v := new(PlayerCreateRequest)
+		json.NewDecoder(r.Body).Decode(v)
+		record(fmt.Sprintf("player %v %v", v.Name, len(v.Videos)))
+		fmt.Fprint(w, `{"code": 200, "status": "success", "player": {"id": "567d3643534b4474087c221e"}}`)
+	})
+}
+
+func TestRecordingSession(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	pipeline := &RecordingPipeline{
+		Presets:      []string{"5bd37808534b441c4acfp001"},
+		HLS:          true,
+		Player:       true,
+		PollInterval: time.Millisecond,
+	}
+
+	store := &FileSessionStore{Dir: t.TempDir()}
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", pipeline)
+	session.StopTimeout = time.Hour
+	session.Store = store
+
+	var event RecordingEvent
+	session.OnComplete = func(e RecordingEvent) {
+		event = e
+	}
+
+	err := session.Start(ctx)
+	if err != nil {
+		t.Fatalf("RecordingSession.Start returned error: %v", err)
+	}
+
+	if err := session.Start(ctx); err != ErrRecordingActive {
+		t.Errorf("RecordingSession.Start returned error: %v, expected %v", err, ErrRecordingActive)
+	}
+
+	saved, _ := store.Load(ctx, "56cec7e2fa63afd0f843567d")
+	if saved == nil || saved.State != RecordingActive || saved.Deadline.IsZero() {
+		t.Errorf("FileSessionStore.Load = %+v, expected active recording with deadline", saved)
+	}
+
+	err = session.Stop(ctx)
+	if err != nil {
+		t.Fatalf("RecordingSession.Stop returned error: %v", err)
+	}
+
+	folder := "/recordings/56cec7e2fa63afd0f843567d/" + session.State().StartedAt.UTC().Format("20060102T150405Z")
+	expected := []string{
+		"start 3600",
+		"stop",
+		"transcode " + folder,
+		"hls",
+		"player rec 2",
+	}
+
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("RecordingSession calls = %v, expected %v", calls, expected)
+	}
+
+	if event.Err != nil || event.Player == nil || event.State.State != RecordingDone || event.State.PlayerID != "567d3643534b4474087c221e" {
+		t.Errorf("RecordingSession event = %+v, expected completed recording", event)
+	}
+
+	saved, _ = store.Load(ctx, "56cec7e2fa63afd0f843567d")
+	if saved.State != RecordingDone || saved.Stage != StageCompleted || len(saved.Renditions) != 2 || saved.Folder != folder {
+		t.Errorf("FileSessionStore.Load = %+v, expected done recording", saved)
+	}
+}
+
+func TestRecordingSessionStopTimeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	done := make(chan RecordingEvent, 1)
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", &RecordingPipeline{PollInterval: time.Millisecond})
+	session.StopTimeout = 10 * time.Millisecond
+	session.OnComplete = func(e RecordingEvent) {
+		done <- e
+	}
+
+	err := session.Start(ctx)
+	if err != nil {
+		t.Fatalf("RecordingSession.Start returned error: %v", err)
+	}
+
+	select {
+	case e := <-done:
+		if e.Err != nil || e.State.State != RecordingDone {
+			t.Errorf("RecordingSession event = %+v, expected done recording", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RecordingSession wasn't stopped after StopTimeout")
+	}
+}
+
+func TestRecordingSessionRestore(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	store := &FileSessionStore{Dir: t.TempDir()}
+	store.Save(ctx, &RecordingState{
+		StreamID: "56cec7e2fa63afd0f843567d",
+		State:    RecordingProcessing,
+		Stage:    StageTranscode,
+		Files:    []string{"5bd37808534b441c4acf7415"},
+	})
+
+	pipeline := &RecordingPipeline{
+		Presets:      []string{"5bd37808534b441c4acfp001"},
+		HLS:          true,
+		PollInterval: time.Millisecond,
+	}
+
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", pipeline)
+	session.Store = store
+
+	err := session.Restore(ctx)
+	if err != nil {
+		t.Fatalf("RecordingSession.Restore returned error: %v", err)
+	}
+
+	if fmt.Sprint(calls) != "[hls]" {
+		t.Errorf("RecordingSession calls = %v, expected only hls", calls)
+	}
+
+	if state := session.State(); state.State != RecordingDone {
+		t.Errorf("RecordingSession state = %v, expected %v", state.State, RecordingDone)
+	}
+}
+
+func TestRecordingSessionFiles(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	mux.HandleFunc("/1/objects/5bd37808534b441c4acf7416", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "object": {"id": "5bd37808534b441c4acf7416", "name": "rec_1.mp4", "status": "ok"}}`)
+	})
+
+	mux.HandleFunc("/1/transcoder/5bd37808534b441c4acf7416", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "transcode 1")
+		fmt.Fprint(w, `{"code": 200, "status": "success", "task_id": "5bd37808534b441c4acf0001"}`)
+	})
+
+	store := &FileSessionStore{Dir: t.TempDir()}
+	store.Save(ctx, &RecordingState{
+		StreamID: "56cec7e2fa63afd0f843567d",
+		State:    RecordingProcessing,
+		Stage:    StageSettle,
+		Folder:   "/recordings/56cec7e2fa63afd0f843567d/5624cd5ac9a492f8b979b63f",
+		Files:    []string{"5bd37808534b441c4acf7415", "5bd37808534b441c4acf7416"},
+	})
+
+	pipeline := &RecordingPipeline{
+		Presets:      []string{"5bd37808534b441c4acfp001"},
+		Player:       true,
+		PollInterval: time.Millisecond,
+	}
+
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", pipeline)
+	session.Store = store
+
+	err := session.Restore(ctx)
+	if err != ErrRecordingFiles {
+		t.Errorf("RecordingSession.Restore returned error: %v, expected %v", err, ErrRecordingFiles)
+	}
+
+	expected := []string{
+		"transcode /recordings/56cec7e2fa63afd0f843567d/5624cd5ac9a492f8b979b63f",
+		"transcode 1",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("RecordingSession calls = %v, expected %v", calls, expected)
+	}
+
+	state := session.State()
+	if state.State != RecordingFailed || state.Stage != StageTranscode || state.PlayerID != "" {
+		t.Errorf("RecordingSession state = %+v, expected failed at player stage", state)
+	}
+}
+
+func TestRecordingSessionFolders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	mux.HandleFunc("/1/streams/rec/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"record": {"status": "Finish", "files": ["5bd37808534b441c4acf7415"]}}`)
+	})
+
+	pipeline := &RecordingPipeline{
+		Presets:      []string{"5bd37808534b441c4acfp001"},
+		Path:         "/vod",
+		PollInterval: time.Millisecond,
+	}
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", pipeline)
+
+	for _, recordID := range []string{"5624cd5ac9a492f8b979b63f", "5624cd5ac9a492f8b979b640"} {
+		_, err := session.Rec(ctx, recordID)
+		if err != nil {
+			t.Fatalf("RecordingSession.Rec returned error: %v", err)
+		}
+	}
+
+	expected := []string{
+		"transcode /vod/5624cd5ac9a492f8b979b63f",
+		"transcode /vod/5624cd5ac9a492f8b979b640",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("RecordingSession calls = %v, expected %v", calls, expected)
+	}
+}
+
+func TestRecordingSessionClose(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handleRecordingPipeline(t, &calls)
+
+	session := NewRecordingSession(client, "56cec7e2fa63afd0f843567d", &RecordingPipeline{PollInterval: time.Millisecond})
+	session.StopTimeout = 20 * time.Millisecond
+
+	err := session.Start(ctx)
+	if err != nil {
+		t.Fatalf("RecordingSession.Start returned error: %v", err)
+	}
+
+	session.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	if state := session.State(); state.State != RecordingActive || fmt.Sprint(calls) != "[start 0]" {
+		t.Errorf("RecordingSession after Close = %v %v, expected active recording without stop", state.State, calls)
+	}
+}
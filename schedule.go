@@ -0,0 +1,327 @@
+package filespot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+)
+
+// ErrScheduleEnded is returned by Scheduler when the schedule has no future occurrences
+var ErrScheduleEnded = errors.New("filespot: schedule has no future occurrences")
+
+var (
+	rruleDays = map[string]time.Weekday{
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+		"SU": time.Sunday,
+	}
+
+	rruleUntilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+)
+
+// Schedule is a recording window of stream, optionally recurring.
+// Recurring occurrences keep wall clock time of Start in Location across DST changes.
+type Schedule struct {
+	Start time.Time
+	// Duration of recording, Stop is used when zero
+	Duration time.Duration
+	Stop     time.Time
+	// Location of recurrence, location of Start by default
+	Location   *time.Location
+	Recurrence *Recurrence
+}
+
+// Recurrence is a subset of RFC 5545 RRULE: FREQ of DAILY or WEEKLY,
+// INTERVAL, BYDAY of WEEKLY, COUNT and UNTIL.
+// See https://tools.ietf.org/html/rfc5545#section-3.3.10
+type Recurrence struct {
+	Freq string
+	// Interval between days or weeks, 1 by default
+	Interval int
+	// ByDay are week days of WEEKLY recurrence, week day of Start by default
+	ByDay []time.Weekday
+	// Count limits number of occurrences when set
+	Count int
+	// Until is the last possible start of occurrence when set
+	Until time.Time
+}
+
+// ParseRRule parses recurrence like "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10",
+// "RRULE:" prefix is allowed. Floating UNTIL is parsed in loc.
+func ParseRRule(s string, loc *time.Location) (*Recurrence, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	r := new(Recurrence)
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("filespot: bad rrule part %q", part)
+		}
+
+		key, value := strings.ToUpper(kv[0]), kv[1]
+		switch key {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("filespot: bad rrule %v %q", key, value)
+			}
+
+			if key == "INTERVAL" {
+				r.Interval = n
+			} else {
+				r.Count = n
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleDays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("filespot: unsupported rrule BYDAY %q", day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "UNTIL":
+			until, err := parseRRuleUntil(value, loc)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return nil, fmt.Errorf("filespot: unsupported rrule WKST %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("filespot: unsupported rrule part %v", key)
+		}
+	}
+
+	err := r.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// String returns recurrence as RRULE value
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, weekday := range r.ByDay {
+			days[i] = strings.ToUpper(weekday.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleUntilLayouts[0]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Validate checks schedule has positive length and supported recurrence
+func (s *Schedule) Validate() error {
+	if s.Start.IsZero() {
+		return errors.New("filespot: schedule start is missing")
+	}
+
+	if s.length() <= 0 {
+		return errors.New("filespot: schedule must have positive duration or stop after start")
+	}
+
+	if s.Recurrence != nil {
+		return s.Recurrence.validate()
+	}
+
+	return nil
+}
+
+// Next returns the first occurrence of schedule which doesn't end before t.
+// The occurrence may be in progress at t. It returns false when schedule has ended.
+func (s *Schedule) Next(t time.Time) (start, stop time.Time, ok bool) {
+	length := s.length()
+	if length <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	if s.Recurrence == nil {
+		start = s.Start
+		stop = start.Add(length)
+		return start, stop, stop.After(t)
+	}
+
+	r := s.Recurrence
+	occurrences := s.occurrences()
+	for n := 1; r.Count == 0 || n <= r.Count; n++ {
+		start = occurrences()
+		if !r.Until.IsZero() && start.After(r.Until) {
+			break
+		}
+
+		stop = start.Add(length)
+		if stop.After(t) {
+			return start, stop, true
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// length returns recording duration
+func (s *Schedule) length() time.Duration {
+	if s.Duration > 0 {
+		return s.Duration
+	}
+
+	if s.Stop.IsZero() {
+		return 0
+	}
+
+	return s.Stop.Sub(s.Start)
+}
+
+// location returns time zone of recurrence
+func (s *Schedule) location() *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+
+	return s.Start.Location()
+}
+
+// occurrences returns iterator of recurrence starts in order
+func (s *Schedule) occurrences() func() time.Time {
+	r := s.Recurrence
+	start := s.Start.In(s.location())
+	year, month, day := start.Date()
+	hour, min, sec := start.Clock()
+	nsec := start.Nanosecond()
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	at := func(days int) time.Time {
+		return time.Date(year, month, day+days, hour, min, sec, nsec, start.Location())
+	}
+
+	if r.Freq == FreqDaily {
+		n := 0
+		return func() time.Time {
+			t := at(n * interval)
+			n++
+			return t
+		}
+	}
+
+	// offsets of week days from Monday of the Start week
+	weekdays := r.ByDay
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{start.Weekday()}
+	}
+
+	offsets := make([]int, 0, len(weekdays))
+	seen := make(map[int]bool)
+	for _, weekday := range weekdays {
+		offset := (int(weekday) + 6) % 7
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Ints(offsets)
+
+	monday := -((int(start.Weekday()) + 6) % 7)
+	week, i := 0, 0
+
+	return func() time.Time {
+		for {
+			if i == len(offsets) {
+				week, i = week+interval, 0
+			}
+
+			days := monday + week*7 + offsets[i]
+			i++
+			if days >= 0 {
+				return at(days)
+			}
+		}
+	}
+}
+
+// validate checks recurrence is supported
+func (r *Recurrence) validate() error {
+	switch r.Freq {
+	case FreqDaily:
+		if len(r.ByDay) > 0 {
+			return errors.New("filespot: rrule BYDAY is supported by WEEKLY only")
+		}
+	case FreqWeekly:
+	default:
+		return fmt.Errorf("filespot: unsupported rrule FREQ %q", r.Freq)
+	}
+
+	if r.Interval < 0 || r.Count < 0 {
+		return errors.New("filespot: rrule INTERVAL and COUNT must be positive")
+	}
+
+	return nil
+}
+
+// parseRRuleUntil parses UNTIL value
+func parseRRuleUntil(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range rruleUntilLayouts {
+		if strings.HasSuffix(layout, "Z") != strings.HasSuffix(value, "Z") {
+			continue
+		}
+
+		zone := loc
+		if strings.HasSuffix(value, "Z") {
+			zone = time.UTC
+		}
+
+		until, err := time.ParseInLocation(layout, value, zone)
+		if err != nil {
+			continue
+		}
+
+		if len(value) == len("20060102") {
+			// date only UNTIL includes the whole day
+			until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+
+		return until, nil
+	}
+
+	return time.Time{}, fmt.Errorf("filespot: bad rrule UNTIL %q", value)
+}
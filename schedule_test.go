@@ -0,0 +1,135 @@
+package filespot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseRRule(t *testing.T) {
+	r, err := ParseRRule("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10;UNTIL=20261231T235959Z", nil)
+	if err != nil {
+		t.Fatalf("ParseRRule returned error: %v", err)
+	}
+
+	expected := &Recurrence{
+		Freq:     FreqWeekly,
+		Interval: 2,
+		ByDay:    []time.Weekday{time.Monday, time.Wednesday},
+		Count:    10,
+		Until:    time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC),
+	}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("ParseRRule = %+v, expected %+v", r, expected)
+	}
+
+	s := "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10;UNTIL=20261231T235959Z"
+	if r.String() != s {
+		t.Errorf("Recurrence.String = %v, expected %v", r.String(), s)
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	rules := []string{
+		"FREQ=MONTHLY",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;BYHOUR=10",
+		"FREQ=DAILY;UNTIL=2026",
+		"FREQ",
+	}
+
+	for _, rule := range rules {
+		if _, err := ParseRRule(rule, nil); err == nil {
+			t.Errorf("ParseRRule(%v) expected error", rule)
+		}
+	}
+}
+
+func TestScheduleNextDaily(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	schedule := &Schedule{
+		Start:      time.Date(2026, 10, 23, 20, 0, 0, 0, berlin),
+		Duration:   time.Hour,
+		Recurrence: &Recurrence{Freq: FreqDaily, Count: 5},
+	}
+
+	tests := []struct {
+		t     time.Time
+		start time.Time
+		ok    bool
+	}{
+		{time.Date(2026, 10, 1, 0, 0, 0, 0, berlin), time.Date(2026, 10, 23, 20, 0, 0, 0, berlin), true},
+		{time.Date(2026, 10, 23, 20, 30, 0, 0, berlin), time.Date(2026, 10, 23, 20, 0, 0, 0, berlin), true},
+		// wall clock time is kept after DST ends on October 25
+		{time.Date(2026, 10, 25, 12, 0, 0, 0, berlin), time.Date(2026, 10, 25, 20, 0, 0, 0, berlin), true},
+		{time.Date(2026, 10, 27, 21, 0, 0, 0, berlin), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		start, stop, ok := schedule.Next(tt.t)
+		if ok != tt.ok || !start.Equal(tt.start) {
+			t.Errorf("Schedule.Next(%v) = %v, %v, expected %v, %v", tt.t, start, ok, tt.start, tt.ok)
+		}
+
+		if ok && stop.Sub(start) != time.Hour {
+			t.Errorf("Schedule.Next(%v) length = %v, expected %v", tt.t, stop.Sub(start), time.Hour)
+		}
+	}
+}
+
+func TestScheduleNextWeekly(t *testing.T) {
+	// Wednesday
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	rule, _ := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR;UNTIL=20261110", time.UTC)
+
+	schedule := &Schedule{
+		Start:      start,
+		Stop:       start.Add(30 * time.Minute),
+		Recurrence: rule,
+	}
+
+	var starts []time.Time
+	after := start
+	for {
+		s, _, ok := schedule.Next(after)
+		if !ok {
+			break
+		}
+		starts = append(starts, s)
+		after = s.Add(time.Hour)
+	}
+
+	expected := []time.Time{
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 6, 9, 0, 0, 0, time.UTC),
+	}
+
+	if !reflect.DeepEqual(starts, expected) {
+		t.Errorf("Schedule.Next occurrences = %v, expected %v", starts, expected)
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+
+	schedules := []*Schedule{
+		{Duration: time.Hour},
+		{Start: start},
+		{Start: start, Stop: start.Add(-time.Hour)},
+		{Start: start, Duration: time.Hour, Recurrence: &Recurrence{Freq: "YEARLY"}},
+	}
+
+	for _, schedule := range schedules {
+		if err := schedule.Validate(); err == nil {
+			t.Errorf("Schedule.Validate(%+v) expected error", schedule)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package filespot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduler records streams according to schedules.
+// One-off schedules are created on the server via StreamsService.CreateSchedule,
+// recurring ones are driven locally by calling StreamsService.Start and Stop from Run.
+// Scheduler is safe for concurrent use.
+type Scheduler struct {
+	Streams StreamsService
+	// StopTimeout is passed to StreamsService.Start
+	StopTimeout int
+	// OnError is called with errors of Start and Stop made by Tick when set
+	OnError func(streamID string, err error)
+
+	mu      sync.Mutex
+	entries map[string]*scheduleEntry
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// scheduleEntry is a locally driven schedule of stream
+type scheduleEntry struct {
+	schedule  *Schedule
+	recording bool
+	// stop of the occurrence being recorded
+	stop time.Time
+	// busy is set while Tick calls API for entry
+	busy bool
+}
+
+// scheduleJob is work of Tick for entry
+type scheduleJob struct {
+	streamID string
+	entry    *scheduleEntry
+	stop     bool
+	start    bool
+	// until is stop of the occurrence to start
+	until time.Time
+	// remove entry when it has no future occurrences
+	remove bool
+}
+
+// NewScheduler returns Scheduler of streams
+func NewScheduler(streams StreamsService) *Scheduler {
+	return &Scheduler{
+		Streams: streams,
+	}
+}
+
+// Schedule schedules recording of stream. One-off schedule is created on the server
+// and its record ID is returned, recurring schedule is added to Scheduler
+// and an empty record ID is returned.
+func (s *Scheduler) Schedule(ctx context.Context, streamID string, schedule *Schedule) (string, error) {
+	err := schedule.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	if schedule.Recurrence != nil {
+		return "", s.Add(streamID, schedule)
+	}
+
+	start, stop, ok := schedule.Next(s.clock())
+	if !ok {
+		return "", ErrScheduleEnded
+	}
+
+	streamScheduleRequest := &StreamScheduleRequest{
+		Start: int(start.Unix()),
+		Stop:  int(stop.Unix()),
+	}
+
+	recordID, _, err := s.Streams.CreateSchedule(ctx, streamID, streamScheduleRequest)
+	return recordID, err
+}
+
+// Add drives recording of stream by schedule locally replacing its previous schedule
+func (s *Scheduler) Add(streamID string, schedule *Schedule) error {
+	err := schedule.Validate()
+	if err != nil {
+		return err
+	}
+
+	if _, _, ok := schedule.Next(s.clock()); !ok {
+		return ErrScheduleEnded
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]*scheduleEntry)
+	}
+
+	entry, ok := s.entries[streamID]
+	if !ok {
+		entry = new(scheduleEntry)
+		s.entries[streamID] = entry
+	}
+	entry.schedule = schedule
+
+	return nil
+}
+
+// Remove removes schedule of stream and stops its recording in progress.
+// Recording being started or stopped by Tick is stopped by Tick.
+func (s *Scheduler) Remove(ctx context.Context, streamID string) error {
+	s.mu.Lock()
+	entry, ok := s.entries[streamID]
+	delete(s.entries, streamID)
+	recording := ok && entry.recording && !entry.busy
+	s.mu.Unlock()
+
+	if !recording {
+		return nil
+	}
+
+	_, _, err := s.Streams.Stop(ctx, streamID)
+	return err
+}
+
+// Tick starts recordings of occurrences in progress and stops finished ones.
+// Schedules without future occurrences are removed. The lock isn't held during
+// API calls and OnError, so OnError may call other methods of Scheduler.
+func (s *Scheduler) Tick(ctx context.Context) {
+	now := s.clock()
+
+	for _, job := range s.jobs(now) {
+		err := s.run(ctx, job)
+		if err != nil {
+			s.fail(job.streamID, err)
+		}
+	}
+}
+
+// Run calls Tick every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobs returns work of Tick at now marking entries of jobs as busy
+func (s *Scheduler) jobs(now time.Time) []scheduleJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []scheduleJob
+	for streamID, entry := range s.entries {
+		if entry.busy {
+			continue
+		}
+
+		job := scheduleJob{streamID: streamID, entry: entry}
+		job.stop = entry.recording && !now.Before(entry.stop)
+
+		start, stop, ok := entry.schedule.Next(now)
+		if !ok {
+			if !entry.recording {
+				delete(s.entries, streamID)
+				continue
+			}
+			job.remove = true
+		}
+
+		job.start = ok && (!entry.recording || job.stop) && !now.Before(start)
+		job.until = stop
+
+		if job.stop || job.start {
+			entry.busy = true
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs
+}
+
+// run calls API for job and updates its entry
+func (s *Scheduler) run(ctx context.Context, job scheduleJob) error {
+	var stopped, started bool
+
+	var err error
+	if job.stop {
+		_, _, err = s.Streams.Stop(ctx, job.streamID)
+		stopped = err == nil
+	}
+
+	if job.start && err == nil {
+		streamStartRequest := &StreamStartRequest{StopTimeout: s.StopTimeout}
+		_, err = s.Streams.Start(ctx, job.streamID, streamStartRequest)
+		started = err == nil
+	}
+
+	s.mu.Lock()
+	entry := job.entry
+	entry.busy = false
+	if stopped {
+		entry.recording = false
+	}
+	if started {
+		entry.recording = true
+		entry.stop = job.until
+	}
+
+	current := s.entries[job.streamID] == entry
+	if current && job.remove && !entry.recording {
+		delete(s.entries, job.streamID)
+	}
+	recording := entry.recording
+	s.mu.Unlock()
+
+	// schedule was removed during the job, Remove left recording to be stopped here
+	if recording && !current {
+		_, _, err = s.Streams.Stop(ctx, job.streamID)
+	}
+
+	return err
+}
+
+// fail reports error of stream
+func (s *Scheduler) fail(streamID string, err error) {
+	if s.OnError != nil {
+		s.OnError(streamID, err)
+	}
+}
+
+// clock returns current time
+func (s *Scheduler) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+
+	return time.Now()
+}
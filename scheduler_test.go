@@ -0,0 +1,202 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeStreams records calls of StreamsService
+type fakeStreams struct {
+	StreamsService
+	calls    []string
+	schedule *StreamScheduleRequest
+	startErr error
+	// onStart is called by Start when set
+	onStart func()
+}
+
+func (f *fakeStreams) Start(ctx context.Context, id string, r *StreamStartRequest) (*http.Response, error) {
+	f.calls = append(f.calls, "start "+id)
+	if f.onStart != nil {
+		f.onStart()
+	}
+	return nil, f.startErr
+}
+
+func (f *fakeStreams) Stop(ctx context.Context, id string) ([]File, *http.Response, error) {
+	f.calls = append(f.calls, "stop "+id)
+	return nil, nil, nil
+}
+
+func (f *fakeStreams) CreateSchedule(ctx context.Context, id string, r *StreamScheduleRequest) (string, *http.Response, error) {
+	f.schedule = r
+	return "5624cd5ac9a492f8b979b63f", nil, nil
+}
+
+func TestSchedulerServerSide(t *testing.T) {
+	streams := new(fakeStreams)
+	scheduler := NewScheduler(streams)
+
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return start.Add(-time.Hour) }
+
+	recordID, err := scheduler.Schedule(ctx, "56cec7e2fa63afd0f843567d", &Schedule{Start: start, Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("Scheduler.Schedule returned error: %v", err)
+	}
+
+	if recordID != "5624cd5ac9a492f8b979b63f" {
+		t.Errorf("Scheduler.Schedule = %v, expected %v", recordID, "5624cd5ac9a492f8b979b63f")
+	}
+
+	expected := StreamScheduleRequest{Start: int(start.Unix()), Stop: int(start.Add(time.Hour).Unix())}
+	if streams.schedule == nil || *streams.schedule != expected {
+		t.Errorf("Streams.CreateSchedule request = %+v, expected %+v", streams.schedule, expected)
+	}
+}
+
+func TestSchedulerTick(t *testing.T) {
+	streams := new(fakeStreams)
+	scheduler := NewScheduler(streams)
+
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	now := start.Add(-time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	schedule := &Schedule{
+		Start:      start,
+		Duration:   time.Hour,
+		Recurrence: &Recurrence{Freq: FreqDaily, Count: 2},
+	}
+
+	recordID, err := scheduler.Schedule(ctx, "56cec7e2fa63afd0f843567d", schedule)
+	if err != nil || recordID != "" {
+		t.Fatalf("Scheduler.Schedule = %v, %v, expected local schedule", recordID, err)
+	}
+
+	steps := []time.Duration{
+		0,
+		2 * time.Minute,
+		30 * time.Minute,
+		61 * time.Minute,
+		24*time.Hour + time.Minute,
+		25*time.Hour + time.Minute,
+		48 * time.Hour,
+	}
+
+	for _, step := range steps {
+		now = start.Add(step - time.Minute)
+		scheduler.Tick(ctx)
+	}
+
+	expected := []string{
+		"start 56cec7e2fa63afd0f843567d",
+		"stop 56cec7e2fa63afd0f843567d",
+		"start 56cec7e2fa63afd0f843567d",
+		"stop 56cec7e2fa63afd0f843567d",
+	}
+
+	if len(streams.calls) != len(expected) {
+		t.Fatalf("Scheduler calls = %v, expected %v", streams.calls, expected)
+	}
+	for i := range expected {
+		if streams.calls[i] != expected[i] {
+			t.Errorf("Scheduler calls = %v, expected %v", streams.calls, expected)
+		}
+	}
+
+	if len(scheduler.entries) != 0 {
+		t.Errorf("Scheduler entries = %v, expected ended schedule removed", len(scheduler.entries))
+	}
+}
+
+func TestSchedulerRemove(t *testing.T) {
+	streams := new(fakeStreams)
+	scheduler := NewScheduler(streams)
+
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return start.Add(time.Minute) }
+
+	err := scheduler.Add("56cec7e2fa63afd0f843567d", &Schedule{Start: start, Duration: time.Hour, Recurrence: &Recurrence{Freq: FreqWeekly}})
+	if err != nil {
+		t.Fatalf("Scheduler.Add returned error: %v", err)
+	}
+
+	scheduler.Tick(ctx)
+	scheduler.Remove(ctx, "56cec7e2fa63afd0f843567d")
+
+	if len(streams.calls) != 2 || streams.calls[1] != "stop 56cec7e2fa63afd0f843567d" {
+		t.Errorf("Scheduler calls = %v, expected start and stop", streams.calls)
+	}
+
+	err = scheduler.Add("56cec7e2fa63afd0f843567d", &Schedule{Start: start.Add(-2 * time.Hour), Duration: time.Hour})
+	if err != ErrScheduleEnded {
+		t.Errorf("Scheduler.Add returned error: %v, expected %v", err, ErrScheduleEnded)
+	}
+}
+
+func TestSchedulerRemoveBusy(t *testing.T) {
+	streams := new(fakeStreams)
+	scheduler := NewScheduler(streams)
+
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return start.Add(time.Minute) }
+
+	err := scheduler.Add("56cec7e2fa63afd0f843567d", &Schedule{Start: start, Duration: time.Hour, Recurrence: &Recurrence{Freq: FreqWeekly}})
+	if err != nil {
+		t.Fatalf("Scheduler.Add returned error: %v", err)
+	}
+
+	// schedule is removed while Tick starts recording
+	streams.onStart = func() {
+		err := scheduler.Remove(ctx, "56cec7e2fa63afd0f843567d")
+		if err != nil {
+			t.Errorf("Scheduler.Remove returned error: %v", err)
+		}
+	}
+	scheduler.Tick(ctx)
+
+	expected := []string{"start 56cec7e2fa63afd0f843567d", "stop 56cec7e2fa63afd0f843567d"}
+	if fmt.Sprint(streams.calls) != fmt.Sprint(expected) || len(scheduler.entries) != 0 {
+		t.Errorf("Scheduler calls = %v with %v entries, expected %v", streams.calls, len(scheduler.entries), expected)
+	}
+}
+
+func TestSchedulerOnErrorRemove(t *testing.T) {
+	streams := &fakeStreams{startErr: errors.New("stream is offline")}
+	scheduler := NewScheduler(streams)
+
+	start := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return start.Add(time.Minute) }
+
+	var failed error
+	scheduler.OnError = func(streamID string, err error) {
+		failed = err
+		scheduler.Remove(ctx, streamID)
+	}
+
+	err := scheduler.Add("56cec7e2fa63afd0f843567d", &Schedule{Start: start, Duration: time.Hour, Recurrence: &Recurrence{Freq: FreqDaily}})
+	if err != nil {
+		t.Fatalf("Scheduler.Add returned error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Tick(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Scheduler.Tick deadlocked calling OnError")
+	}
+
+	if failed != streams.startErr || len(scheduler.entries) != 0 {
+		t.Errorf("Scheduler.OnError = %v with %v entries, expected %v and schedule removed", failed, len(scheduler.entries), streams.startErr)
+	}
+}
@@ -0,0 +1,160 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	secureLinkSecret = "LinkSecret"
+	// secureLinkHash is the hash of TestTempSecure fixture
+	secureLinkHash = "79cc441e5f602b37a1294a59ea8ae3deddeed63c1de8580f66c1323f91487aa9"
+)
+
+var secureTestLink = &Link{
+	ID:     "58ee48ca534b4409844c8f7a",
+	Href:   "example.com/temp/5cdd8082ef3db56742cd704a",
+	Secure: true,
+}
+
+// handleSecure serves Temp.Secure returning the fixture hash for any request
+func handleSecure(t *testing.T, calls *int) {
+	mux.HandleFunc("/1/temp/58ee48ca534b4409844c8f7a/secure", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		secureLinkRequest := new(SecureLinkRequest)
+		json.NewDecoder(r.Body).Decode(secureLinkRequest)
+
+		fmt.Fprintf(w, `{
+            "code": 200,
+            "status": "success",
+            "hash": "%v",
+            "url": "https://example.com/temp/5cdd8082ef3db56742cd704a?hash=%v&timestamp=%v"
+        }`, secureLinkHash, secureLinkHash, secureLinkRequest.TS)
+	})
+}
+
+// testSigner joins its arguments so tests can check what is signed
+func testSigner(secret, path, ip, timestamp string) string {
+	return fmt.Sprintf("%v:%v:%v:%v", secret, path, ip, timestamp)
+}
+
+func TestSecureLinkGenerate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleSecure(t, &calls)
+
+	generator := NewSecureLinkGenerator(secureLinkSecret, client.Temp)
+	generator.Sign = testSigner
+
+	secureLink, err := generator.Generate(secureTestLink, "188.111.110.11", time.Unix(1558030392, 0))
+	if err != nil {
+		t.Errorf("SecureLinkGenerator.Generate returned error: %v", err)
+	}
+
+	hash := "LinkSecret:/temp/5cdd8082ef3db56742cd704a:188.111.110.11:1558030392"
+	expected := &SecureLink{
+		Hash: hash,
+		URL:  "https://example.com/temp/5cdd8082ef3db56742cd704a?hash=LinkSecret%3A%2Ftemp%2F5cdd8082ef3db56742cd704a%3A188.111.110.11%3A1558030392&timestamp=1558030392",
+	}
+	if !reflect.DeepEqual(secureLink, expected) {
+		t.Errorf("SecureLinkGenerator.Generate = %v, expected %v", secureLink, expected)
+	}
+
+	secureLinkRequest := &SecureLinkRequest{IP: "188.111.110.11", TS: 1558030392}
+	secureLink, err = generator.Secure(ctx, secureTestLink, secureLinkRequest)
+	if err != nil {
+		t.Errorf("SecureLinkGenerator.Secure returned error: %v", err)
+	}
+
+	if calls != 0 || !reflect.DeepEqual(secureLink, expected) {
+		t.Errorf("SecureLinkGenerator.Secure = %v with %v API calls, expected %v without calls", secureLink, calls, expected)
+	}
+}
+
+func TestSecureLinkDefault(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleSecure(t, &calls)
+
+	// local signing is off until Sign is set
+	generator := NewSecureLinkGenerator(secureLinkSecret, client.Temp)
+
+	_, err := generator.Generate(secureTestLink, "188.111.110.11", time.Now())
+	if err != ErrNoSigner {
+		t.Errorf("SecureLinkGenerator.Generate error = %v, expected %v", err, ErrNoSigner)
+	}
+
+	secureLinkRequest := &SecureLinkRequest{IP: "188.111.110.11", TS: 1558030392}
+	secureLink, err := generator.Secure(ctx, secureTestLink, secureLinkRequest)
+	if err != nil {
+		t.Errorf("SecureLinkGenerator.Secure returned error: %v", err)
+	}
+
+	if calls != 1 || secureLink == nil || secureLink.Hash != secureLinkHash {
+		t.Errorf("SecureLinkGenerator.Secure = %v with %v API calls, expected API hash", secureLink, calls)
+	}
+}
+
+func TestSecureLinkFallback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleSecure(t, &calls)
+
+	generator := NewSecureLinkGenerator("", client.Temp)
+	generator.Sign = testSigner
+
+	secureLinkRequest := &SecureLinkRequest{IP: "188.111.110.11", TS: 1558030392}
+	secureLink, err := generator.Secure(ctx, secureTestLink, secureLinkRequest)
+	if err != nil {
+		t.Errorf("SecureLinkGenerator.Secure returned error: %v", err)
+	}
+
+	if calls != 1 || secureLink == nil {
+		t.Errorf("SecureLinkGenerator.Secure API calls = %v, expected %v", calls, 1)
+	}
+
+	_, err = generator.Generate(secureTestLink, secureLinkRequest.IP, time.Now())
+	if err != ErrNoSecret {
+		t.Errorf("SecureLinkGenerator.Generate error = %v, expected %v", err, ErrNoSecret)
+	}
+}
+
+func TestSecureLinkVerify(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	handleSecure(t, &calls)
+
+	generator := NewSecureLinkGenerator(secureLinkSecret, client.Temp)
+	generator.Sign = testSigner
+
+	err := generator.Verify(ctx, secureTestLink)
+	if err == nil {
+		t.Errorf("SecureLinkGenerator.Verify expected error of mismatched hash")
+	}
+
+	generator.Sign = func(secret, path, ip, timestamp string) string {
+		return secureLinkHash
+	}
+
+	err = generator.Verify(ctx, secureTestLink)
+	if err != nil {
+		t.Errorf("SecureLinkGenerator.Verify returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("SecureLinkGenerator.Verify API calls = %v, expected %v", calls, 2)
+	}
+}
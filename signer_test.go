@@ -0,0 +1,92 @@
+package filespot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignerSign(t *testing.T) {
+	signer := NewSigner(apiuserid, apiuserkey)
+
+	// hmac-sha256 of "GET+api.platformcraft.ru/1/objects?apiuserid=test&timestamp=1558030392"
+	expected := "128d0b22888a638b740c7bd097cac47a5fdec8f922061b1efc6a30b87537851a"
+	hash := signer.Sign(http.MethodGet, "api.platformcraft.ru", "/1/objects", "1558030392")
+
+	if hash != expected {
+		t.Errorf("Signer.Sign = %v, expected %v", hash, expected)
+	}
+
+	c := NewClient(apiuserid, apiuserkey)
+	if clientHash := c.generateHash(http.MethodGet, "/1/objects", "1558030392"); clientHash != expected {
+		t.Errorf("Client.generateHash = %v, expected %v", clientHash, expected)
+	}
+}
+
+func TestSignerVerify(t *testing.T) {
+	c := NewClient(apiuserid, apiuserkey)
+	signer := NewSigner(apiuserid, apiuserkey)
+
+	req, _ := c.NewRequest(ctx, http.MethodGet, "/1/objects", nil)
+	if err := signer.Verify(req, time.Minute); err != nil {
+		t.Errorf("Signer.Verify returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(q url.Values)
+		err    error
+	}{
+		{"missing hash", func(q url.Values) { q.Del("hash") }, ErrSignatureMissing},
+		{"unknown user", func(q url.Values) { q.Set("apiuserid", "other") }, ErrSignatureUser},
+		{"expired", func(q url.Values) {
+			q.Set("timestamp", strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+		}, ErrSignatureExpired},
+		{"tampered", func(q url.Values) {
+			q.Set("timestamp", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+		}, ErrSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		r := req.Clone(ctx)
+		q := r.URL.Query()
+		tt.modify(q)
+		r.URL.RawQuery = q.Encode()
+
+		if err := signer.Verify(r, time.Minute); err != tt.err {
+			t.Errorf("Signer.Verify %v = %v, expected %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	signer := NewSigner(apiuserid, apiuserkey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := signer.Verify(r, time.Minute); err != nil {
+			t.Errorf("Signer.Verify returned error: %v", err)
+		}
+
+		if r.URL.Query().Get("name") != "test.mp4" {
+			t.Errorf("request query name = %v, expected %v", r.URL.Query().Get("name"), "test.mp4")
+		}
+
+		fmt.Fprintf(w, `{}`)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &Transport{Signer: signer}}
+	resp, err := httpClient.Get(server.URL + "/1/objects?name=test.mp4")
+	if err != nil {
+		t.Fatalf("Transport returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Transport status = %v, expected %v", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,113 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxAttempts limits Do to a single retry after clock skew correction
+	maxAttempts = 2
+	// minSkew is the smallest skew change worth a retry, Date header has seconds precision
+	minSkew = time.Second
+)
+
+// errNotRewindable is returned when request body can't be sent again
+var errNotRewindable = errors.New("filespot: request body can't be rewound")
+
+// ClockSkew returns measured offset of the API server clock.
+// Positive value means the server clock is ahead of the local one.
+// It's applied to the timestamp of every signed request.
+func (c *Client) ClockSkew() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.skew))
+}
+
+// now returns local time corrected by ClockSkew
+func (c *Client) now() time.Time {
+	return time.Now().Add(c.ClockSkew())
+}
+
+// isTimestampError reports whether err is the API rejection of request timestamp or hash
+func isTimestampError(err error) bool {
+	errorResponse, ok := err.(*ErrorResponse)
+	if !ok {
+		return false
+	}
+
+	switch errorResponse.Response.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+	default:
+		return false
+	}
+
+	msg := strings.ToLower(errorResponse.MsgUser + " " + errorResponse.MsgDev)
+	return strings.Contains(msg, "timestamp")
+}
+
+// adjustSkew derives server time from the Date header of rejected response.
+// It returns true when skew was changed and request is worth a retry.
+func (c *Client) adjustSkew(ctx context.Context, resp *http.Response, err error) bool {
+	if resp == nil || !isTimestampError(err) {
+		return false
+	}
+
+	serverTime, perr := http.ParseTime(resp.Header.Get("Date"))
+	if perr != nil {
+		return false
+	}
+
+	// Date is truncated to seconds, the middle of the second is the best guess
+	skew := serverTime.Add(time.Second / 2).Sub(time.Now()).Round(time.Second)
+	previous := c.ClockSkew()
+
+	change := skew - previous
+	if change < 0 {
+		change = -change
+	}
+	if change < minSkew {
+		return false
+	}
+
+	atomic.StoreInt64(&c.skew, int64(skew))
+
+	if c.logEnabled(ctx, slog.LevelWarn) {
+		c.Logger.LogAttrs(ctx, slog.LevelWarn, "filespot clock skew detected",
+			slog.Duration("skew", skew),
+			slog.Duration("previous", previous),
+		)
+	}
+
+	return true
+}
+
+// resignRequest returns copy of req with fresh timestamp and hash
+func (c *Client) resignRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errNotRewindable
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+
+	timestamp := strconv.FormatInt(c.now().Unix(), 10)
+
+	q := retry.URL.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("hash", c.generateHash(req.Method, retry.URL.Path, timestamp))
+	retry.URL.RawQuery = q.Encode()
+
+	return retry, nil
+}
@@ -0,0 +1,114 @@
+package filespot
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// skewedServer rejects requests with timestamp differing from server time by more than a minute
+func skewedServer(t *testing.T, serverTime time.Time, calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"filespot"}`+"\n" {
+			t.Errorf("request body = %q, expected %q", body, `{"name":"filespot"}`)
+		}
+
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+
+		ts, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+		if d := serverTime.Unix() - ts; d > 60 || d < -60 {
+			http.Error(w, `{
+                "code": 400,
+                "status": "fail",
+                "msg_user": "Api ID, timestamp and hash are required.",
+                "msg_dev": "Check Api id, timestamp and hash."
+            }`, http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprintf(w, `{"name":"filespot"}`)
+	}
+}
+
+func TestDoClockSkew(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/objects", skewedServer(t, time.Now().Add(-2*time.Hour), &calls))
+
+	req, _ := client.NewRequest(ctx, http.MethodPut, "/1/objects", &RequestBody{Name: "filespot"})
+	_, err := client.Do(ctx, req, new(RequestBody))
+	if err != nil {
+		t.Errorf("Do returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Do requests = %v, expected %v", calls, 2)
+	}
+
+	skew := client.ClockSkew()
+	if skew > -2*time.Hour+2*time.Second || skew < -2*time.Hour-2*time.Second {
+		t.Errorf("ClockSkew = %v, expected about %v", skew, -2*time.Hour)
+	}
+
+	// skew is kept for subsequent requests
+	calls = 0
+	req, _ = client.NewRequest(ctx, http.MethodPut, "/1/objects", &RequestBody{Name: "filespot"})
+	_, err = client.Do(ctx, req, new(RequestBody))
+	if err != nil || calls != 1 {
+		t.Errorf("Do requests = %v with error %v, expected %v without error", calls, err, 1)
+	}
+}
+
+func TestDoClockSkewRetriesOnce(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		// server clock moves away on every request
+		w.Header().Set("Date", time.Now().Add(time.Duration(calls)*time.Hour).UTC().Format(http.TimeFormat))
+		http.Error(w, `{"code": 400, "status": "fail", "msg_user": "Api ID, timestamp and hash are required."}`, http.StatusBadRequest)
+	})
+
+	req, _ := client.NewRequest(ctx, http.MethodGet, "/1/objects", nil)
+	_, err := client.Do(ctx, req, new(RequestBody))
+	if err == nil {
+		t.Error("Do returns without expected error")
+	}
+
+	if calls != 2 {
+		t.Errorf("Do requests = %v, expected %v", calls, 2)
+	}
+}
+
+func TestDoWithoutSkew(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		http.Error(w, `{"code": 400, "status": "fail", "msg_user": "Api ID, timestamp and hash are required."}`, http.StatusBadRequest)
+	})
+
+	req, _ := client.NewRequest(ctx, http.MethodGet, "/1/objects", nil)
+	client.Do(ctx, req, new(RequestBody))
+
+	if calls != 1 {
+		t.Errorf("Do requests = %v, expected %v", calls, 1)
+	}
+
+	if client.ClockSkew() != 0 {
+		t.Errorf("ClockSkew = %v, expected %v", client.ClockSkew(), 0)
+	}
+}
@@ -16,7 +16,7 @@ type StreamsService interface {
 	Delete(context.Context, string) (*http.Response, error)
 	Start(context.Context, string, *StreamStartRequest) (*http.Response, error)
 	Stop(context.Context, string) ([]File, *http.Response, error)
-	CreateSchedule(context.Context, string) (string, *http.Response, error)
+	CreateSchedule(context.Context, string, *StreamScheduleRequest) (string, *http.Response, error)
 	Rec(context.Context, string) (*Record, *http.Response, error)
 	DeleteSchedule(context.Context, string, string) (*http.Response, error)
 }
@@ -52,8 +52,24 @@ type File struct {
 
 // Record represents a platformcraft Record
 type Record struct {
-	Status string   `json:"status"`
-	Files  []string `json:"files"`
+	Status RecordStatus `json:"status"`
+	Files  []string     `json:"files"`
+}
+
+// RecordStatus is status of scheduled Record
+type RecordStatus string
+
+// Record statuses
+const (
+	RecordWait    RecordStatus = "Wait"
+	RecordProcess RecordStatus = "Process"
+	RecordFinish  RecordStatus = "Finish"
+	RecordError   RecordStatus = "Error"
+)
+
+// Done reports whether recording has finished or failed
+func (s RecordStatus) Done() bool {
+	return s == RecordFinish || s == RecordError
 }
 
 // streamsRoot represents a List root
@@ -87,6 +103,13 @@ type StreamStartRequest struct {
 	StopTimeout int `json:"stop_timeout"`
 }
 
+// StreamScheduleRequest identifies recording window for the CreateSchedule request.
+// Times are unix timestamps.
+type StreamScheduleRequest struct {
+	Start int `json:"start,omitempty"`
+	Stop  int `json:"stop,omitempty"`
+}
+
 // List of Streams
 func (c StreamsCli) List(ctx context.Context) ([]Stream, *http.Response, error) {
 	req, err := c.client.NewRequest(ctx, http.MethodGet, streamsBasePath, nil)
@@ -192,10 +215,10 @@ func (c StreamsCli) Stop(ctx context.Context, id string) ([]File, *http.Response
 }
 
 // CreateSchedule returns record_id
-func (c StreamsCli) CreateSchedule(ctx context.Context, id string) (string, *http.Response, error) {
+func (c StreamsCli) CreateSchedule(ctx context.Context, id string, streamScheduleRequest *StreamScheduleRequest) (string, *http.Response, error) {
 	endpointURL := streamsBasePath + "/rec/schedule/new/" + id
 
-	req, err := c.client.NewRequest(ctx, http.MethodPost, endpointURL, nil)
+	req, err := c.client.NewRequest(ctx, http.MethodPost, endpointURL, streamScheduleRequest)
 	if err != nil {
 		return "", nil, err
 	}
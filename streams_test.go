@@ -1,6 +1,7 @@
 package filespot
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -259,6 +260,14 @@ func TestStreamsCreateSchedule(t *testing.T) {
 			t.Errorf("Streams.CreateSchedule request method = %v, expected %v", r.Method, m)
 		}
 
+		v := new(StreamScheduleRequest)
+		json.NewDecoder(r.Body).Decode(v)
+
+		expected := &StreamScheduleRequest{Start: 1540000000, Stop: 1540003600}
+		if !reflect.DeepEqual(v, expected) {
+			t.Errorf("Streams.CreateSchedule request body = %+v, expected %+v", v, expected)
+		}
+
 		fmt.Fprintf(w, `{
             "code": 200,
             "status": "success",
@@ -266,7 +275,7 @@ func TestStreamsCreateSchedule(t *testing.T) {
         }`)
 	})
 
-	recordID, _, err := client.Streams.CreateSchedule(ctx, "56cec7e2fa63afd0f843567d")
+	recordID, _, err := client.Streams.CreateSchedule(ctx, "56cec7e2fa63afd0f843567d", &StreamScheduleRequest{Start: 1540000000, Stop: 1540003600})
 	if err != nil {
 		t.Errorf("Streams.CreateSchedule returned error: %v", err)
 	}
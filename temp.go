@@ -10,7 +10,7 @@ const tempBasePath = "/1/temp"
 // TempService implements interface with API /temp endpoint.
 // See https://doc.platformcraft.ru/filespot/api/en/#temp
 type TempService interface {
-	List(context.Context, interface{}) ([]Link, *http.Response, error)
+	List(context.Context, *TempListParams) ([]Link, *http.Response, error)
 	Get(context.Context, string) (*Link, *http.Response, error)
 	Create(context.Context, *LinkCreateRequest) (*Link, *http.Response, error)
 	Delete(context.Context, string) (*http.Response, error)
@@ -68,12 +68,12 @@ type linkRoot struct {
 // TempListParams identifies as query params of List request
 type TempListParams struct {
 	ObjectID string `url:"object_id,omitempty"`
-	ForSale  bool   `json:"for_sale,omitempty"`
-	Secure   bool   `json:"secure,omitempty"`
+	ForSale  bool   `url:"for_sale,omitempty"`
+	Secure   bool   `url:"secure,omitempty"`
 }
 
 // List of Links
-func (c TempCli) List(ctx context.Context, params interface{}) ([]Link, *http.Response, error) {
+func (c TempCli) List(ctx context.Context, params *TempListParams) ([]Link, *http.Response, error) {
 	path, err := addParams(tempBasePath, params)
 	if err != nil {
 		return nil, nil, err
@@ -113,6 +113,11 @@ func (c TempCli) Get(ctx context.Context, id string) (*Link, *http.Response, err
 
 // Create Link
 func (c TempCli) Create(ctx context.Context, linkCreateRequest *LinkCreateRequest) (*Link, *http.Response, error) {
+	err := validateGeo(linkCreateRequest.Geo)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req, err := c.client.NewRequest(ctx, http.MethodPost, tempBasePath, linkCreateRequest)
 	if err != nil {
 		return nil, nil, err
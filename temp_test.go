@@ -17,6 +17,10 @@ func TestTempList(t *testing.T) {
 			t.Errorf("Temp.List request method = %v, expected %v", r.Method, m)
 		}
 
+		if secure := r.URL.Query().Get("secure"); secure != "true" {
+			t.Errorf("Temp.List secure = %v, expected %v", secure, "true")
+		}
+
 		fmt.Fprintf(w, `{
             "code": 200,
             "status": "success",
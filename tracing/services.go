@@ -0,0 +1,398 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/droff/filespot"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// objects traces filespot.ObjectsService
+type objects struct {
+	*tracer
+	next filespot.ObjectsService
+}
+
+func (s *objects) List(ctx context.Context, params *filespot.ObjectsListParams) ([]filespot.Object, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.objects.list")
+	objects, resp, err := s.next.List(ctx, params)
+	end(span, resp, err, attribute.Int("filespot.count", len(objects)))
+
+	return objects, resp, err
+}
+
+func (s *objects) Get(ctx context.Context, id string) (*filespot.Object, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.objects.get", ObjectIDKey.String(id))
+	object, resp, err := s.next.Get(ctx, id)
+	end(span, resp, err)
+
+	return object, resp, err
+}
+
+// Create traces the upload with file size
+func (s *objects) Create(ctx context.Context, objectCreateRequest *filespot.ObjectCreateRequest) (*filespot.Object, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.objects.create", attribute.String("filespot.file", objectCreateRequest.File))
+	if info, err := os.Stat(objectCreateRequest.File); err == nil {
+		span.SetAttributes(attribute.Int64("filespot.file_size", info.Size()))
+	}
+
+	object, resp, err := s.next.Create(ctx, objectCreateRequest)
+	if object != nil {
+		span.SetAttributes(ObjectIDKey.String(object.ID))
+	}
+	end(span, resp, err)
+
+	return object, resp, err
+}
+
+func (s *objects) Update(ctx context.Context, id string, objectUpdateRequest *filespot.ObjectUpdateRequest) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.objects.update", ObjectIDKey.String(id))
+	resp, err := s.next.Update(ctx, id, objectUpdateRequest)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+func (s *objects) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.objects.delete", ObjectIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+// temp traces filespot.TempService
+type temp struct {
+	*tracer
+	next filespot.TempService
+}
+
+func (s *temp) List(ctx context.Context, params *filespot.TempListParams) ([]filespot.Link, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.temp.list")
+	links, resp, err := s.next.List(ctx, params)
+	end(span, resp, err, attribute.Int("filespot.count", len(links)))
+
+	return links, resp, err
+}
+
+func (s *temp) Get(ctx context.Context, id string) (*filespot.Link, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.temp.get", LinkIDKey.String(id))
+	link, resp, err := s.next.Get(ctx, id)
+	if link != nil {
+		span.SetAttributes(ObjectIDKey.String(link.ObjectID))
+	}
+	end(span, resp, err)
+
+	return link, resp, err
+}
+
+func (s *temp) Create(ctx context.Context, linkCreateRequest *filespot.LinkCreateRequest) (*filespot.Link, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.temp.create", ObjectIDKey.String(linkCreateRequest.ObjectID))
+	link, resp, err := s.next.Create(ctx, linkCreateRequest)
+	if link != nil {
+		span.SetAttributes(LinkIDKey.String(link.ID))
+	}
+	end(span, resp, err)
+
+	return link, resp, err
+}
+
+func (s *temp) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.temp.delete", LinkIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+func (s *temp) Secure(ctx context.Context, id string, secureLinkRequest *filespot.SecureLinkRequest) (*filespot.SecureLink, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.temp.secure", LinkIDKey.String(id))
+	secureLink, resp, err := s.next.Secure(ctx, id, secureLinkRequest)
+	end(span, resp, err)
+
+	return secureLink, resp, err
+}
+
+// streams traces filespot.StreamsService
+type streams struct {
+	*tracer
+	next filespot.StreamsService
+}
+
+func (s *streams) List(ctx context.Context) ([]filespot.Stream, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.list")
+	streams, resp, err := s.next.List(ctx)
+	end(span, resp, err, attribute.Int("filespot.count", len(streams)))
+
+	return streams, resp, err
+}
+
+func (s *streams) Get(ctx context.Context, id string) (*filespot.Stream, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.get", StreamIDKey.String(id))
+	stream, resp, err := s.next.Get(ctx, id)
+	end(span, resp, err)
+
+	return stream, resp, err
+}
+
+func (s *streams) Create(ctx context.Context, streamCreateRequest *filespot.StreamCreateRequest) (*filespot.Stream, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.create")
+	stream, resp, err := s.next.Create(ctx, streamCreateRequest)
+	if stream != nil {
+		span.SetAttributes(StreamIDKey.String(stream.ID))
+	}
+	end(span, resp, err)
+
+	return stream, resp, err
+}
+
+func (s *streams) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.delete", StreamIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+func (s *streams) Start(ctx context.Context, id string, streamStartRequest *filespot.StreamStartRequest) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.start", StreamIDKey.String(id))
+	resp, err := s.next.Start(ctx, id, streamStartRequest)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+func (s *streams) Stop(ctx context.Context, id string) ([]filespot.File, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.stop", StreamIDKey.String(id))
+	files, resp, err := s.next.Stop(ctx, id)
+	end(span, resp, err, attribute.Int("filespot.count", len(files)))
+
+	return files, resp, err
+}
+
+func (s *streams) CreateSchedule(ctx context.Context, id string, streamScheduleRequest *filespot.StreamScheduleRequest) (string, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.create_schedule", StreamIDKey.String(id))
+	recordID, resp, err := s.next.CreateSchedule(ctx, id, streamScheduleRequest)
+	end(span, resp, err, RecordIDKey.String(recordID))
+
+	return recordID, resp, err
+}
+
+func (s *streams) Rec(ctx context.Context, id string) (*filespot.Record, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.rec", RecordIDKey.String(id))
+	record, resp, err := s.next.Rec(ctx, id)
+	end(span, resp, err)
+
+	return record, resp, err
+}
+
+func (s *streams) DeleteSchedule(ctx context.Context, streamID string, recordID string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.streams.delete_schedule", StreamIDKey.String(streamID), RecordIDKey.String(recordID))
+	resp, err := s.next.DeleteSchedule(ctx, streamID, recordID)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+// players traces filespot.PlayersService
+type players struct {
+	*tracer
+	next filespot.PlayersService
+}
+
+func (s *players) List(ctx context.Context, params *filespot.PlayersListParams) ([]filespot.Player, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.players.list")
+	players, resp, err := s.next.List(ctx, params)
+	end(span, resp, err, attribute.Int("filespot.count", len(players)))
+
+	return players, resp, err
+}
+
+func (s *players) Get(ctx context.Context, id string) (*filespot.Player, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.players.get", PlayerIDKey.String(id))
+	player, resp, err := s.next.Get(ctx, id)
+	end(span, resp, err)
+
+	return player, resp, err
+}
+
+func (s *players) Create(ctx context.Context, playerCreateRequest *filespot.PlayerCreateRequest) (*filespot.Player, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.players.create")
+	player, resp, err := s.next.Create(ctx, playerCreateRequest)
+	if player != nil {
+		span.SetAttributes(PlayerIDKey.String(player.ID))
+	}
+	end(span, resp, err)
+
+	return player, resp, err
+}
+
+func (s *players) Update(ctx context.Context, id string, playerUpdateRequest *filespot.PlayerUpdateRequest) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.players.update", PlayerIDKey.String(id))
+	resp, err := s.next.Update(ctx, id, playerUpdateRequest)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+func (s *players) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.players.delete", PlayerIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+// download traces filespot.DownloadService
+type download struct {
+	*tracer
+	next filespot.DownloadService
+}
+
+func (s *download) Create(ctx context.Context, params interface{}) (*filespot.Download, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.download.create")
+	download, resp, err := s.next.Create(ctx, params)
+	if download != nil {
+		span.SetAttributes(TaskIDKey.String(download.TaskID))
+	}
+	end(span, resp, err)
+
+	return download, resp, err
+}
+
+// downloadTasks traces filespot.DownloadTasksService
+type downloadTasks struct {
+	*tracer
+	next filespot.DownloadTasksService
+}
+
+func (s *downloadTasks) List(ctx context.Context, params *filespot.TasksListParams) ([]filespot.Task, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.download_tasks.list")
+	tasks, resp, err := s.next.List(ctx, params)
+	end(span, resp, err, attribute.Int("filespot.count", len(tasks)))
+
+	return tasks, resp, err
+}
+
+func (s *downloadTasks) Get(ctx context.Context, id string) (*filespot.Task, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.download_tasks.get", TaskIDKey.String(id))
+	task, resp, err := s.next.Get(ctx, id)
+	if task != nil {
+		span.SetAttributes(attribute.String("filespot.task_status", task.Status))
+	}
+	end(span, resp, err)
+
+	return task, resp, err
+}
+
+func (s *downloadTasks) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.download_tasks.delete", TaskIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+// transcoder traces filespot.TranscoderService
+type transcoder struct {
+	*tracer
+	next filespot.TranscoderService
+}
+
+func (s *transcoder) Presets(ctx context.Context) ([]filespot.Preset, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder.presets")
+	presets, resp, err := s.next.Presets(ctx)
+	end(span, resp, err, attribute.Int("filespot.count", len(presets)))
+
+	return presets, resp, err
+}
+
+func (s *transcoder) Create(ctx context.Context, id string, transcoderCreateRequest *filespot.TranscoderCreateRequest) (*filespot.Transcoder, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder.create", ObjectIDKey.String(id))
+	t, resp, err := s.next.Create(ctx, id, transcoderCreateRequest)
+	if t != nil {
+		span.SetAttributes(TaskIDKey.String(t.TaskID))
+	}
+	end(span, resp, err)
+
+	return t, resp, err
+}
+
+func (s *transcoder) Concat(ctx context.Context, transcoderConcatRequest *filespot.TranscoderConcatRequest) (*filespot.Transcoder, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder.concat", attribute.StringSlice("filespot.object_ids", transcoderConcatRequest.Files))
+	t, resp, err := s.next.Concat(ctx, transcoderConcatRequest)
+	if t != nil {
+		span.SetAttributes(TaskIDKey.String(t.TaskID))
+	}
+	end(span, resp, err)
+
+	return t, resp, err
+}
+
+func (s *transcoder) HLS(ctx context.Context, id string, transcoderHLSRequest *filespot.TranscoderHLSRequest) (*filespot.Transcoder, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder.hls", ObjectIDKey.String(id))
+	t, resp, err := s.next.HLS(ctx, id, transcoderHLSRequest)
+	if t != nil {
+		span.SetAttributes(TaskIDKey.String(t.TaskID))
+	}
+	end(span, resp, err)
+
+	return t, resp, err
+}
+
+// transcoderTasks traces filespot.TranscoderTasksService
+type transcoderTasks struct {
+	*tracer
+	next filespot.TranscoderTasksService
+}
+
+func (s *transcoderTasks) List(ctx context.Context, params *filespot.TasksListParams) ([]filespot.Task, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder_tasks.list")
+	tasks, resp, err := s.next.List(ctx, params)
+	end(span, resp, err, attribute.Int("filespot.count", len(tasks)))
+
+	return tasks, resp, err
+}
+
+func (s *transcoderTasks) Get(ctx context.Context, id string) (*filespot.Task, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder_tasks.get", TaskIDKey.String(id))
+	task, resp, err := s.next.Get(ctx, id)
+	if task != nil {
+		span.SetAttributes(attribute.String("filespot.task_status", task.Status))
+	}
+	end(span, resp, err)
+
+	return task, resp, err
+}
+
+func (s *transcoderTasks) HLS(ctx context.Context, id string) (*filespot.Task, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder_tasks.hls", TaskIDKey.String(id))
+	task, resp, err := s.next.HLS(ctx, id)
+	end(span, resp, err)
+
+	return task, resp, err
+}
+
+func (s *transcoderTasks) Delete(ctx context.Context, id string) (*http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.transcoder_tasks.delete", TaskIDKey.String(id))
+	resp, err := s.next.Delete(ctx, id)
+	end(span, resp, err)
+
+	return resp, err
+}
+
+// storage traces filespot.StorageService
+type storage struct {
+	*tracer
+	next filespot.StorageService
+}
+
+func (s *storage) Get(ctx context.Context) (*filespot.Storage, *http.Response, error) {
+	ctx, span := s.start(ctx, "filespot.storage.get")
+	storage, resp, err := s.next.Get(ctx)
+	end(span, resp, err)
+
+	return storage, resp, err
+}
@@ -0,0 +1,212 @@
+// Package tracing instruments filespot.Client with OpenTelemetry spans.
+//
+// Every service call gets a span named after the operation (e.g. filespot.objects.create),
+// every HTTP attempt gets a child span and W3C trace context is propagated on outgoing requests:
+//
+//	c := filespot.NewClient(apiUserID, apiUserKey)
+//	tracing.Instrument(c)
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/droff/filespot"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/droff/filespot/tracing"
+
+// Span attributes
+const (
+	ObjectIDKey   = attribute.Key("filespot.object_id")
+	LinkIDKey     = attribute.Key("filespot.link_id")
+	PlayerIDKey   = attribute.Key("filespot.player_id")
+	StreamIDKey   = attribute.Key("filespot.stream_id")
+	RecordIDKey   = attribute.Key("filespot.record_id")
+	TaskIDKey     = attribute.Key("filespot.task_id")
+	ErrorCodeKey  = attribute.Key("filespot.error_code")
+	AttemptKey    = attribute.Key("filespot.attempt")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// Option configures Instrument
+type Option func(*config)
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets TracerProvider, the global one is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithPropagator sets propagator of outgoing requests, W3C trace context by default
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// tracer creates filespot spans
+type tracer struct {
+	trace.Tracer
+}
+
+// Instrument wraps services of c with tracing decorators,
+// attaches attempt spans to c.Observer and propagates trace context
+// through the HTTP client of c.
+func Instrument(c *filespot.Client, opts ...Option) {
+	cfg := &config{
+		provider:   otel.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	t := &tracer{cfg.provider.Tracer(instrumentationName)}
+
+	c.Objects = &objects{t, c.Objects}
+	c.Temp = &temp{t, c.Temp}
+	c.Streams = &streams{t, c.Streams}
+	c.Players = &players{t, c.Players}
+	c.Download = &download{t, c.Download}
+	c.DownloadTasks = &downloadTasks{t, c.DownloadTasks}
+	c.Transcoder = &transcoder{t, c.Transcoder}
+	c.TranscoderTasks = &transcoderTasks{t, c.TranscoderTasks}
+	c.Storage = &storage{t, c.Storage}
+
+	c.Observer = &observer{t, c.Observer}
+
+	httpClient := http.DefaultClient
+	if c.HTTPClient() != nil {
+		httpClient = c.HTTPClient()
+	}
+
+	// copy http.Client so a shared one (e.g. http.DefaultClient) is left untouched
+	propagating := *httpClient
+	propagating.Transport = &transport{
+		base:       httpClient.Transport,
+		propagator: cfg.propagator,
+	}
+	c.SetHTTPClient(&propagating)
+}
+
+// WaitTask calls filespot.WaitTask inside a filespot.tasks.wait span.
+// When tasks are instrumented every poll is a child span.
+func WaitTask(ctx context.Context, tasks filespot.TaskGetter, id string, interval time.Duration) (*filespot.Task, error) {
+	t := &tracer{otel.GetTracerProvider().Tracer(instrumentationName)}
+	if s, ok := tasks.(interface{ spanTracer() *tracer }); ok {
+		t = s.spanTracer()
+	}
+
+	ctx, span := t.start(ctx, "filespot.tasks.wait", TaskIDKey.String(id))
+	task, err := filespot.WaitTask(ctx, tasks, id, interval)
+	if task != nil {
+		span.SetAttributes(attribute.String("filespot.task_status", task.Status))
+	}
+	end(span, nil, err)
+
+	return task, err
+}
+
+// spanTracer returns tracer of an instrumented service
+func (t *tracer) spanTracer() *tracer {
+	return t
+}
+
+// start starts a client span
+func (t *tracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// end records response and error of the call and ends span
+func end(span trace.Span, resp *http.Response, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+
+	if resp != nil {
+		span.SetAttributes(StatusCodeKey.Int(resp.StatusCode))
+	}
+
+	if err != nil {
+		if errorResponse, ok := err.(*filespot.ErrorResponse); ok {
+			span.SetAttributes(ErrorCodeKey.Int64(int64(errorResponse.Code)))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// observer creates a span for every HTTP attempt
+type observer struct {
+	*tracer
+	next filespot.RequestObserver
+}
+
+// ObserveRequest implements filespot.RequestObserver
+func (o *observer) ObserveRequest(ctx context.Context, info *filespot.RequestInfo) {
+	finish := time.Now()
+
+	name := "filespot.http.request"
+	if info.Attempt > 1 {
+		name = "filespot.http.retry"
+	}
+
+	_, span := o.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(finish.Add(-info.Latency)),
+		trace.WithAttributes(
+			attribute.String("http.request.method", info.Method),
+			attribute.String("url.path", info.Path),
+			attribute.String("filespot.endpoint", info.Endpoint),
+			AttemptKey.Int(info.Attempt),
+		),
+	)
+
+	if info.Status != 0 {
+		span.SetAttributes(StatusCodeKey.Int(info.Status))
+	}
+	if info.ErrorCode != 0 {
+		span.SetAttributes(ErrorCodeKey.Int64(int64(info.ErrorCode)))
+	}
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	}
+	span.End(trace.WithTimestamp(finish))
+
+	if o.next != nil {
+		o.next.ObserveRequest(ctx, info)
+	}
+}
+
+// transport injects trace context into outgoing requests
+type transport struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	req = req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	return base.RoundTrip(req)
+}
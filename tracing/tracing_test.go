@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/droff/filespot"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setup(t *testing.T, mux *http.ServeMux) (*filespot.Client, *tracetest.SpanRecorder) {
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := filespot.NewClient("test", "APIUserKey")
+	client.BaseURL, _ = url.Parse(server.URL)
+
+	recorder := tracetest.NewSpanRecorder()
+	Instrument(client, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+
+	return client, recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestInstrumentSpans(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/objects/56787f0c044dfe226b000001", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") == "" {
+			t.Error("request traceparent header is missing")
+		}
+
+		fmt.Fprintf(w, `{"object": {"id": "56787f0c044dfe226b000001"}}`)
+	})
+
+	client, recorder := setup(t, mux)
+
+	_, _, err := client.Objects.Get(context.Background(), "56787f0c044dfe226b000001")
+	if err != nil {
+		t.Errorf("Objects.Get returned error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %v, expected %v", len(spans), 2)
+	}
+
+	attempt, operation := spans[0], spans[1]
+	if operation.Name() != "filespot.objects.get" {
+		t.Errorf("span name = %v, expected %v", operation.Name(), "filespot.objects.get")
+	}
+
+	if v := attr(operation, ObjectIDKey).AsString(); v != "56787f0c044dfe226b000001" {
+		t.Errorf("span %v = %v, expected %v", ObjectIDKey, v, "56787f0c044dfe226b000001")
+	}
+
+	if v := attr(operation, StatusCodeKey).AsInt64(); v != http.StatusOK {
+		t.Errorf("span %v = %v, expected %v", StatusCodeKey, v, http.StatusOK)
+	}
+
+	if attempt.Name() != "filespot.http.request" || attempt.Parent().SpanID() != operation.SpanContext().SpanID() {
+		t.Errorf("attempt span = %v, expected child filespot.http.request", attempt.Name())
+	}
+}
+
+func TestInstrumentErrorCode(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/temp/58ee48ca534b4409844c8f7a", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 404, "status": "fail"}`, http.StatusNotFound)
+	})
+
+	client, recorder := setup(t, mux)
+
+	_, _, err := client.Temp.Get(context.Background(), "58ee48ca534b4409844c8f7a")
+	if err == nil {
+		t.Error("Temp.Get returns without expected error")
+	}
+
+	spans := recorder.Ended()
+	operation := spans[len(spans)-1]
+
+	if v := attr(operation, ErrorCodeKey).AsInt64(); v != 404 {
+		t.Errorf("span %v = %v, expected %v", ErrorCodeKey, v, 404)
+	}
+}
+
+func TestWaitTask(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/transcoder_tasks/5b0ef0f5534b44566dba3bbd", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"task": {"id": "5b0ef0f5534b44566dba3bbd", "status": "Completed"}}`)
+	})
+
+	client, recorder := setup(t, mux)
+
+	_, err := WaitTask(context.Background(), client.TranscoderTasks, "5b0ef0f5534b44566dba3bbd", time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitTask returned error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	wait := spans[len(spans)-1]
+	if wait.Name() != "filespot.tasks.wait" {
+		t.Errorf("span name = %v, expected %v", wait.Name(), "filespot.tasks.wait")
+	}
+
+	get := spans[len(spans)-2]
+	if get.Name() != "filespot.transcoder_tasks.get" || get.Parent().SpanID() != wait.SpanContext().SpanID() {
+		t.Errorf("poll span = %v, expected child filespot.transcoder_tasks.get", get.Name())
+	}
+}
+
+func TestInstrumentKeepsDefaultClient(t *testing.T) {
+	client := filespot.NewClient("test", "APIUserKey")
+	Instrument(client)
+
+	if http.DefaultClient.Transport != nil {
+		t.Error("Instrument modified http.DefaultClient")
+	}
+}
@@ -29,12 +29,12 @@ type Transcoder struct {
 
 // Preset represents a platformcraft Preset
 type Preset struct {
-	ID         string                     `json:"id"`
-	Name       string                     `json:"name"`
-	Container  string                     `json:"container"`
-	Video      map[string]string          `json:"video"`
-	Audio      map[string]string          `json:"audio"`
-	Watermarks map[string]watermarkParams `json:"watermarks"`
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Container  string                   `json:"container"`
+	Video      *VideoSettings           `json:"video"`
+	Audio      *AudioSettings           `json:"audio"`
+	Watermarks map[string]WatermarkSlot `json:"watermarks"`
 }
 
 // presetsRoot represents a Presets root
@@ -45,12 +45,12 @@ type presetsRoot struct {
 
 // TranscoderCreateRequest identifies params for the Create request
 type TranscoderCreateRequest struct {
-	Presets     []string  `json:"presets"`
-	Path        string    `json:"path"`
-	Watermarks  watermark `json:"watermarks"`
-	DelOriginal bool      `json:"del_original"`
-	Start       int       `json:"start"`
-	Duration    int       `json:"duration"`
+	Presets     []string   `json:"presets"`
+	Path        string     `json:"path"`
+	Watermarks  Watermarks `json:"watermarks"`
+	DelOriginal bool       `json:"del_original"`
+	Start       int        `json:"start"`
+	Duration    int        `json:"duration"`
 }
 
 // TranscoderConcatRequest identifies params for the Concat request
@@ -66,9 +66,6 @@ type TranscoderHLSRequest struct {
 	SegmentDuration int      `json:"segment_duration"`
 }
 
-type watermark map[string]string
-type watermarkParams map[string]string
-
 // Presets Transcoder
 func (c TranscoderCli) Presets(ctx context.Context) ([]Preset, *http.Response, error) {
 	endpointURL := transcoderBasePath + "/presets"
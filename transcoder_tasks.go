@@ -10,7 +10,7 @@ const transcoderTasksBasePath = "/1/transcoder_tasks"
 // TranscoderTasksService implements interface with API /transcoder_tasks endpoint.
 // See https://doc.platformcraft.ru/filespot/api/en/#transcoder_tasks
 type TranscoderTasksService interface {
-	List(context.Context) ([]Task, *http.Response, error)
+	List(context.Context, *TasksListParams) ([]Task, *http.Response, error)
 	Get(context.Context, string) (*Task, *http.Response, error)
 	HLS(context.Context, string) (*Task, *http.Response, error)
 	Delete(context.Context, string) (*http.Response, error)
@@ -22,8 +22,13 @@ type TranscoderTasksCli struct {
 }
 
 // List returns list of all transcoders tasks
-func (c TranscoderTasksCli) List(ctx context.Context) ([]Task, *http.Response, error) {
-	req, err := c.client.NewRequest(ctx, http.MethodGet, transcoderTasksBasePath, nil)
+func (c TranscoderTasksCli) List(ctx context.Context, params *TasksListParams) ([]Task, *http.Response, error) {
+	path, err := addParams(transcoderTasksBasePath, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, nil, err
 	}
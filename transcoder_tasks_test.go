@@ -56,7 +56,7 @@ func TestTranscoderTasksList(t *testing.T) {
         }`)
 	})
 
-	tasks, _, err := client.TranscoderTasks.List(ctx)
+	tasks, _, err := client.TranscoderTasks.List(ctx, nil)
 	if err != nil {
 		t.Errorf("TranscoderTasks.List returned error: %v", err)
 	}
@@ -133,59 +133,59 @@ func TestTranscoderPresets(t *testing.T) {
 			ID:        "566b0fbf044dfe64f2000002",
 			Name:      "System preset: Generic 1080p",
 			Container: "mp4",
-			Video: map[string]string{
-				"codec":      "H.264",
-				"bit_rate":   "5400",
-				"fps":        "29.97",
-				"max_width":  "1920",
-				"max_height": "1080",
+			Video: &VideoSettings{
+				Codec:     "H.264",
+				BitRate:   5400,
+				FPS:       29.97,
+				MaxWidth:  1920,
+				MaxHeight: 1080,
 			},
-			Audio: map[string]string{
-				"codec":       "AAC",
-				"bit_rate":    "160",
-				"sample_rate": "44100",
-				"channels":    "2",
+			Audio: &AudioSettings{
+				Codec:      "AAC",
+				BitRate:    160,
+				SampleRate: 44100,
+				Channels:   2,
 			},
-			Watermarks: map[string]watermarkParams{
+			Watermarks: map[string]WatermarkSlot{
 				"BottomLeft": {
-					"horizontal_align":  "Left",
-					"horizontal_offset": "10%",
-					"vertical_align":    "Bottom",
-					"vertical_offset":   "10%",
-					"max_height":        "10%",
-					"max_width":         "10%",
-					"opacity":           "100",
-					"sizing_policy":     "ShrinkToFit",
+					HorizontalAlign:  "Left",
+					HorizontalOffset: "10%",
+					VerticalAlign:    "Bottom",
+					VerticalOffset:   "10%",
+					MaxHeight:        "10%",
+					MaxWidth:         "10%",
+					Opacity:          100,
+					SizingPolicy:     "ShrinkToFit",
 				},
 				"BottomRight": {
-					"horizontal_align":  "Right",
-					"horizontal_offset": "10%",
-					"vertical_align":    "Bottom",
-					"vertical_offset":   "10%",
-					"max_height":        "10%",
-					"max_width":         "10%",
-					"opacity":           "100",
-					"sizing_policy":     "ShrinkToFit",
+					HorizontalAlign:  "Right",
+					HorizontalOffset: "10%",
+					VerticalAlign:    "Bottom",
+					VerticalOffset:   "10%",
+					MaxHeight:        "10%",
+					MaxWidth:         "10%",
+					Opacity:          100,
+					SizingPolicy:     "ShrinkToFit",
 				},
 				"Full": {
-					"horizontal_align":  "Left",
-					"horizontal_offset": "0%",
-					"vertical_align":    "Top",
-					"vertical_offset":   "0%",
-					"max_height":        "100%",
-					"max_width":         "100%",
-					"opacity":           "100",
-					"sizing_policy":     "Fit",
+					HorizontalAlign:  "Left",
+					HorizontalOffset: "0%",
+					VerticalAlign:    "Top",
+					VerticalOffset:   "0%",
+					MaxHeight:        "100%",
+					MaxWidth:         "100%",
+					Opacity:          100,
+					SizingPolicy:     "Fit",
 				},
 				"TopRight": {
-					"horizontal_align":  "Right",
-					"horizontal_offset": "10%",
-					"vertical_align":    "Top",
-					"vertical_offset":   "10%",
-					"max_height":        "10%",
-					"max_width":         "10%",
-					"opacity":           "100",
-					"sizing_policy":     "ShrinkToFit",
+					HorizontalAlign:  "Right",
+					HorizontalOffset: "10%",
+					VerticalAlign:    "Top",
+					VerticalOffset:   "10%",
+					MaxHeight:        "10%",
+					MaxWidth:         "10%",
+					Opacity:          100,
+					SizingPolicy:     "ShrinkToFit",
 				},
 			},
 		},
@@ -193,18 +193,18 @@ func TestTranscoderPresets(t *testing.T) {
 			ID:        "566b0fbf044dfe64f2000003",
 			Name:      "System preset: Generic 720p",
 			Container: "mp4",
-			Video: map[string]string{
-				"codec":      "H.264",
-				"bit_rate":   "2400",
-				"fps":        "29.97",
-				"max_width":  "1280",
-				"max_height": "720",
+			Video: &VideoSettings{
+				Codec:     "H.264",
+				BitRate:   2400,
+				FPS:       29.97,
+				MaxWidth:  1280,
+				MaxHeight: 720,
 			},
-			Audio: map[string]string{
-				"codec":       "AAC",
-				"bit_rate":    "160",
-				"sample_rate": "44100",
-				"channels":    "2",
+			Audio: &AudioSettings{
+				Codec:      "AAC",
+				BitRate:    160,
+				SampleRate: 44100,
+				Channels:   2,
 			},
 			Watermarks: nil,
 		},
@@ -212,18 +212,18 @@ func TestTranscoderPresets(t *testing.T) {
 			ID:        "566b0fbf044dfe64f2000004",
 			Name:      "System preset: Generic 480p 16:9",
 			Container: "mp4",
-			Video: map[string]string{
-				"codec":      "H.264",
-				"bit_rate":   "1200",
-				"fps":        "29.97",
-				"max_width":  "854",
-				"max_height": "480",
+			Video: &VideoSettings{
+				Codec:     "H.264",
+				BitRate:   1200,
+				FPS:       29.97,
+				MaxWidth:  854,
+				MaxHeight: 480,
 			},
-			Audio: map[string]string{
-				"codec":       "AAC",
-				"bit_rate":    "128",
-				"sample_rate": "44100",
-				"channels":    "2",
+			Audio: &AudioSettings{
+				Codec:      "AAC",
+				BitRate:    128,
+				SampleRate: 44100,
+				Channels:   2,
 			},
 			Watermarks: nil,
 		},
@@ -256,7 +256,7 @@ func TestTranscoderCreate(t *testing.T) {
 	transcoderCreateRequest := &TranscoderCreateRequest{
 		Presets:     []string{"5676a27cf9cb101634000002", "5676a27cf9cb101634000003"},
 		Path:        "/test",
-		Watermarks:  watermark{"Full": "5adfa939534b446a607d9937"},
+		Watermarks:  Watermarks{{ObjectID: "5adfa939534b446a607d9937", Position: WatermarkFull}},
 		DelOriginal: false,
 	}
 
@@ -0,0 +1,220 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultSecureLinkTTL = time.Hour
+
+// ErrUnknownCountry is returned by CountryLookup when IP has no country
+var ErrUnknownCountry = errors.New("filespot: country of ip is unknown")
+
+// CountryLookup resolves IP to ISO 3166-1 alpha-2 country code
+type CountryLookup interface {
+	Country(ip net.IP) (string, error)
+}
+
+// CountryLookupFunc is a function implementing CountryLookup
+type CountryLookupFunc func(ip net.IP) (string, error)
+
+// Country implements CountryLookup
+func (f CountryLookupFunc) Country(ip net.IP) (string, error) {
+	return f(ip)
+}
+
+// viewerKey is context key of Viewer
+type viewerKey struct{}
+
+// Viewer is an admitted viewer passed to the next handler of ViewerAccess
+type Viewer struct {
+	IP         net.IP
+	Country    string
+	Link       *Link
+	SecureLink *SecureLink
+}
+
+// ViewerFromContext returns Viewer admitted by ViewerAccess
+func ViewerFromContext(ctx context.Context) (*Viewer, bool) {
+	viewer, ok := ctx.Value(viewerKey{}).(*Viewer)
+	return viewer, ok
+}
+
+// ViewerAccess is http.Handler middleware which rejects viewers from countries
+// not allowed by Geo of the requested Player or Link and issues a per-IP secure link
+// to admitted ones. The link is available to the next handler via ViewerFromContext.
+type ViewerAccess struct {
+	Lookup CountryLookup
+	// Resolve returns Link to be secured and Geo policy of the request,
+	// e.g. Geo of a Player or the Link itself, see PlayerResolver and LinkResolver
+	Resolve func(r *http.Request) (*Link, Geo, error)
+	// Secure issues secure links, see SecureLinkRequest.IP
+	Secure *SecureLinkGenerator
+	// TTL of issued secure links, one hour by default
+	TTL time.Duration
+	// TrustForwardedFor takes client IP from X-Forwarded-For header appended by a trusted proxy.
+	// The rightmost entry is used unless TrustedProxies are set.
+	TrustForwardedFor bool
+	// TrustedProxies are networks of proxies in front of the handler. The client IP is the
+	// rightmost address of X-Forwarded-For and RemoteAddr chain which isn't a trusted proxy.
+	TrustedProxies []*net.IPNet
+	// Denied handles rejected viewers, it responds with 403 Forbidden by default
+	Denied http.Handler
+}
+
+// LinkResolver returns ViewerAccess.Resolve which gets Link by ID from request and uses its Geo
+func LinkResolver(temp TempService, id func(r *http.Request) string) func(r *http.Request) (*Link, Geo, error) {
+	return func(r *http.Request) (*Link, Geo, error) {
+		link, _, err := temp.Get(r.Context(), id(r))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return link, link.Geo, nil
+	}
+}
+
+// PlayerResolver returns ViewerAccess.Resolve which gets Player by ID from request and applies
+// its Geo to Link returned by link, e.g. LinkResolver of a link to the player video.
+// Viewer needs access granted by both Geo of the Player and Geo of the Link.
+func PlayerResolver(players PlayersService, id func(r *http.Request) string, link func(r *http.Request) (*Link, Geo, error)) func(r *http.Request) (*Link, Geo, error) {
+	return func(r *http.Request) (*Link, Geo, error) {
+		player, _, err := players.Get(r.Context(), id(r))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		l, geo, err := link(r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// empty Geo doesn't restrict access, while its Intersect lists every country
+		switch {
+		case len(geo) == 0:
+			geo = player.Geo
+		case len(player.Geo) > 0:
+			geo = geo.Intersect(player.Geo)
+		}
+
+		return l, geo, nil
+	}
+}
+
+// Handler returns middleware admitting allowed viewers to next
+func (v *ViewerAccess) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := v.clientIP(r)
+		if ip == nil {
+			http.Error(w, "bad client address", http.StatusBadRequest)
+			return
+		}
+
+		link, geo, err := v.Resolve(r)
+		if err != nil {
+			if notFound(err) {
+				http.NotFound(w, r)
+				return
+			}
+
+			http.Error(w, "can't resolve media", http.StatusBadGateway)
+			return
+		}
+
+		country := ""
+		if len(geo) > 0 {
+			country, err = v.Lookup.Country(ip)
+			if err != nil || !geo.Allows(country) {
+				v.deny(w, r)
+				return
+			}
+		}
+
+		ttl := v.TTL
+		if ttl <= 0 {
+			ttl = defaultSecureLinkTTL
+		}
+
+		secureLinkRequest := &SecureLinkRequest{
+			IP: ip.String(),
+			TS: int(time.Now().Add(ttl).Unix()),
+		}
+
+		secureLink, err := v.Secure.Secure(r.Context(), link, secureLinkRequest)
+		if err != nil {
+			http.Error(w, "can't secure link", http.StatusBadGateway)
+			return
+		}
+
+		viewer := &Viewer{
+			IP:         ip,
+			Country:    country,
+			Link:       link,
+			SecureLink: secureLink,
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), viewerKey{}, viewer)))
+	})
+}
+
+// deny rejects viewer
+func (v *ViewerAccess) deny(w http.ResponseWriter, r *http.Request) {
+	if v.Denied != nil {
+		v.Denied.ServeHTTP(w, r)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+// clientIP returns IP of the viewer.
+// Entries left of the client are set by the client itself, so they are never used.
+func (v *ViewerAccess) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+
+	if !v.TrustForwardedFor {
+		return remote
+	}
+
+	var chain []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		chain = append(chain, strings.Split(header, ",")...)
+	}
+
+	if len(v.TrustedProxies) == 0 {
+		if len(chain) == 0 {
+			return remote
+		}
+		return net.ParseIP(strings.TrimSpace(chain[len(chain)-1]))
+	}
+
+	ip := remote
+	for i := len(chain) - 1; i >= 0 && v.trustedProxy(ip); i-- {
+		ip = net.ParseIP(strings.TrimSpace(chain[i]))
+	}
+
+	return ip
+}
+
+// trustedProxy reports whether ip belongs to TrustedProxies
+func (v *ViewerAccess) trustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range v.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
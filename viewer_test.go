@@ -0,0 +1,174 @@
+package filespot
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testViewerAccess(geo Geo) http.Handler {
+	countries := map[string]string{
+		"188.111.110.11": "RU",
+		"8.8.8.8":        "US",
+	}
+
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	secure := NewSecureLinkGenerator(secureLinkSecret, nil)
+	secure.Sign = testSigner
+
+	access := &ViewerAccess{
+		Lookup: CountryLookupFunc(func(ip net.IP) (string, error) {
+			country, ok := countries[ip.String()]
+			if !ok {
+				return "", ErrUnknownCountry
+			}
+			return country, nil
+		}),
+		Resolve: func(r *http.Request) (*Link, Geo, error) {
+			return secureTestLink, geo, nil
+		},
+		Secure:            secure,
+		TrustForwardedFor: true,
+		TrustedProxies:    []*net.IPNet{proxies},
+	}
+
+	return access.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer, ok := ViewerFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no viewer", http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, "%v %v", viewer.Country, viewer.SecureLink.URL)
+	}))
+}
+
+func TestViewerAccess(t *testing.T) {
+	handler := testViewerAccess(Geo{"EU": {"RU": true}})
+
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		status     int
+	}{
+		{"188.111.110.11:4000", "", http.StatusOK},
+		{"10.0.0.1:4000", "188.111.110.11, 10.0.0.2", http.StatusOK},
+		{"10.0.0.1:4000", "188.111.110.11", http.StatusOK},
+		// spoofed entry left of the real client is ignored
+		{"10.0.0.1:4000", "188.111.110.11, 8.8.8.8", http.StatusForbidden},
+		{"10.0.0.1:4000", "188.111.110.11, 8.8.8.8, 10.0.0.2", http.StatusForbidden},
+		// header of a client connecting directly isn't trusted
+		{"8.8.8.8:4000", "188.111.110.11", http.StatusForbidden},
+		{"8.8.8.8:4000", "", http.StatusForbidden},
+		{"127.0.0.1:4000", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/watch", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("ViewerAccess %v status = %v, expected %v", tt.remoteAddr, w.Code, tt.status)
+		}
+
+		if w.Code == http.StatusOK && !strings.HasPrefix(w.Body.String(), "RU https://example.com/temp/5cdd8082ef3db56742cd704a?hash=") {
+			t.Errorf("ViewerAccess body = %v, expected secure link", w.Body.String())
+		}
+	}
+}
+
+func TestViewerAccessRightmostForwardedFor(t *testing.T) {
+	access := &ViewerAccess{TrustForwardedFor: true}
+
+	r := httptest.NewRequest(http.MethodGet, "/watch", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	r.Header.Set("X-Forwarded-For", "188.111.110.11, 8.8.8.8")
+
+	if ip := access.clientIP(r); ip.String() != "8.8.8.8" {
+		t.Errorf("ViewerAccess.clientIP = %v, expected %v", ip, "8.8.8.8")
+	}
+}
+
+func TestViewerAccessUnrestricted(t *testing.T) {
+	handler := testViewerAccess(nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/watch", nil)
+	r.RemoteAddr = "127.0.0.1:4000"
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ViewerAccess status = %v, expected %v", w.Code, http.StatusOK)
+	}
+}
+
+func TestPlayerResolver(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c221e", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code": 200, "status": "success", "player": {"id": "567d3643534b4474087c221e", "geo": {"EU": {"RU": true, "DE": true}}}}`)
+	})
+
+	mux.HandleFunc("/1/players/567d3643534b4474087c2210", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code": 404, "status": "error"}`)
+	})
+
+	id := func(r *http.Request) string {
+		return strings.TrimPrefix(r.URL.Path, "/watch/")
+	}
+
+	tests := []struct {
+		linkGeo Geo
+		allowed []string
+		denied  []string
+	}{
+		{nil, []string{"RU", "DE"}, []string{"US"}},
+		{Geo{"EU": {"RU": true}}, []string{"RU"}, []string{"DE", "US"}},
+	}
+
+	for _, tt := range tests {
+		resolve := PlayerResolver(client.Players, id, func(r *http.Request) (*Link, Geo, error) {
+			return secureTestLink, tt.linkGeo, nil
+		})
+
+		link, geo, err := resolve(httptest.NewRequest(http.MethodGet, "/watch/567d3643534b4474087c221e", nil))
+		if err != nil {
+			t.Fatalf("PlayerResolver returned error: %v", err)
+		}
+
+		if link != secureTestLink {
+			t.Errorf("PlayerResolver link = %+v, expected %+v", link, secureTestLink)
+		}
+
+		for _, country := range tt.allowed {
+			if !geo.Allows(country) {
+				t.Errorf("PlayerResolver Geo %v denies %v, expected it allowed", geo, country)
+			}
+		}
+
+		for _, country := range tt.denied {
+			if geo.Allows(country) {
+				t.Errorf("PlayerResolver Geo %v allows %v, expected it denied", geo, country)
+			}
+		}
+	}
+
+	resolve := PlayerResolver(client.Players, id, nil)
+	_, _, err := resolve(httptest.NewRequest(http.MethodGet, "/watch/567d3643534b4474087c2210", nil))
+	if !notFound(err) {
+		t.Errorf("PlayerResolver error = %v, expected not found", err)
+	}
+}
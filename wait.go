@@ -0,0 +1,170 @@
+package filespot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWaitInterval       = 5 * time.Second
+	defaultObjectsConcurrency = 4
+)
+
+var (
+	// ErrTaskFailed is returned by WaitTask when task finished with Error status
+	ErrTaskFailed = errors.New("filespot: task failed")
+	// ErrRecordFailed is returned by WaitRecord when record finished with Error status
+	ErrRecordFailed = errors.New("filespot: record failed")
+)
+
+// TaskGetter gets Task by ID.
+// It's implemented by DownloadTasksService and TranscoderTasksService.
+type TaskGetter interface {
+	Get(context.Context, string) (*Task, *http.Response, error)
+}
+
+// RecordGetter gets Record by ID.
+// It's implemented by StreamsService.
+type RecordGetter interface {
+	Rec(context.Context, string) (*Record, *http.Response, error)
+}
+
+// WaitTask polls task every interval until it leaves Progress status.
+// It returns the finished Task, and ErrTaskFailed when the task ends with Error.
+func WaitTask(ctx context.Context, tasks TaskGetter, id string, interval time.Duration) (*Task, error) {
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		task, _, err := tasks.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch task.Status {
+		case TaskCompleted:
+			return task, nil
+		case TaskError:
+			return task, ErrTaskFailed
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitRecord polls record every interval until recording is done.
+// It returns the finished Record, and ErrRecordFailed when the record ends with Error.
+func WaitRecord(ctx context.Context, streams RecordGetter, recordID string, interval time.Duration) (*Record, error) {
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		record, _, err := streams.Rec(ctx, recordID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch record.Status {
+		case RecordFinish:
+			return record, nil
+		case RecordError:
+			return record, ErrRecordFailed
+		}
+
+		select {
+		case <-ctx.Done():
+			return record, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecordObjects gets objects of record files keeping their order.
+// At most concurrency requests are made at once, 4 by default.
+// It returns the first error and cancels outstanding requests.
+func RecordObjects(ctx context.Context, objects ObjectsService, record *Record, concurrency int) ([]Object, error) {
+	result := make([]Object, len(record.Files))
+
+	err := forEach(ctx, len(record.Files), concurrency, func(ctx context.Context, i int) error {
+		object, _, err := objects.Get(ctx, record.Files[i])
+		if err != nil {
+			return err
+		}
+
+		result[i] = *object
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// forEach calls fn for indexes 0..n-1 making at most concurrency calls at once, 4 by default.
+// It returns the first error and cancels ctx of outstanding calls.
+func forEach(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	if concurrency <= 0 {
+		concurrency = defaultObjectsConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := fn(ctx, i)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,148 @@
+package filespot
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWaitTask(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/transcoder_tasks/5b0ef0f5534b44566dba3bbd", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		status := TaskProgress
+		if calls > 2 {
+			status = TaskCompleted
+		}
+
+		fmt.Fprintf(w, `{"task": {"id": "5b0ef0f5534b44566dba3bbd", "status": "%v"}}`, status)
+	})
+
+	task, err := WaitTask(ctx, client.TranscoderTasks, "5b0ef0f5534b44566dba3bbd", time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitTask returned error: %v", err)
+	}
+
+	if task.Status != TaskCompleted || calls != 3 {
+		t.Errorf("WaitTask status = %v after %v calls, expected %v after %v calls", task.Status, calls, TaskCompleted, 3)
+	}
+}
+
+func TestWaitTaskFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/download_tasks/5b0ef0f5534b44566dba3bbd", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"task": {"id": "5b0ef0f5534b44566dba3bbd", "status": "Error"}}`)
+	})
+
+	_, err := WaitTask(ctx, client.DownloadTasks, "5b0ef0f5534b44566dba3bbd", time.Millisecond)
+	if err != ErrTaskFailed {
+		t.Errorf("WaitTask error = %v, expected %v", err, ErrTaskFailed)
+	}
+}
+
+func TestWaitRecord(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/1/streams/rec/5624cd5ac9a492f8b979b63f", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		status := RecordProcess
+		if calls > 2 {
+			status = RecordFinish
+		}
+
+		fmt.Fprintf(w, `{"record": {"status": "%v", "files": ["5bd37808534b441c4acf7415"]}}`, status)
+	})
+
+	record, err := WaitRecord(ctx, client.Streams, "5624cd5ac9a492f8b979b63f", time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitRecord returned error: %v", err)
+	}
+
+	if record.Status != RecordFinish || calls != 3 {
+		t.Errorf("WaitRecord status = %v after %v calls, expected %v after %v calls", record.Status, calls, RecordFinish, 3)
+	}
+}
+
+func TestWaitRecordFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/streams/rec/5624cd5ac9a492f8b979b63f", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"record": {"status": "Error"}}`)
+	})
+
+	_, err := WaitRecord(ctx, client.Streams, "5624cd5ac9a492f8b979b63f", time.Millisecond)
+	if err != ErrRecordFailed {
+		t.Errorf("WaitRecord error = %v, expected %v", err, ErrRecordFailed)
+	}
+}
+
+func TestRecordObjects(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var (
+		mu             sync.Mutex
+		active, maxAct int
+	)
+
+	mux.HandleFunc("/1/objects/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxAct {
+			maxAct = active
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		id := strings.TrimPrefix(r.URL.Path, "/1/objects/")
+		if id == "5bd37808534b441c4acf0000" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 404, "status": "error"}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"object": {"id": %q}}`, id)
+	})
+
+	record := &Record{Status: RecordFinish}
+	for i := 1; i <= 6; i++ {
+		record.Files = append(record.Files, fmt.Sprintf("5bd37808534b441c4acf000%v", i))
+	}
+
+	objects, err := RecordObjects(ctx, client.Objects, record, 2)
+	if err != nil {
+		t.Fatalf("RecordObjects returned error: %v", err)
+	}
+
+	for i, object := range objects {
+		if object.ID != record.Files[i] {
+			t.Errorf("RecordObjects[%v] = %v, expected %v", i, object.ID, record.Files[i])
+		}
+	}
+
+	if maxAct > 2 {
+		t.Errorf("RecordObjects concurrency = %v, expected at most %v", maxAct, 2)
+	}
+
+	record.Files = append(record.Files, "5bd37808534b441c4acf0000")
+	_, err = RecordObjects(ctx, client.Objects, record, 2)
+	if err == nil {
+		t.Error("RecordObjects expected error")
+	}
+}
@@ -0,0 +1,195 @@
+package filespot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Watermark positions of system presets, position is a name of preset watermark slot.
+// Other positions, e.g. top left corner or center, are slots of custom presets.
+const (
+	WatermarkFull        WatermarkPosition = "Full"
+	WatermarkTopRight    WatermarkPosition = "TopRight"
+	WatermarkBottomLeft  WatermarkPosition = "BottomLeft"
+	WatermarkBottomRight WatermarkPosition = "BottomRight"
+)
+
+// ErrNotPNG is returned by TranscoderCreateRequest.UploadWatermark when the file isn't PNG image
+var ErrNotPNG = errors.New("filespot: watermark isn't PNG image")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// WatermarkPosition is a name of preset watermark slot
+type WatermarkPosition string
+
+// Watermark is an image object placed into watermark slot of preset.
+// TranscoderService.Create accepts only image of every slot, so margins, scale,
+// opacity and time window of watermark can't be set per request: they are
+// settings of the slot, see WatermarkSlot, and need a preset of their own.
+type Watermark struct {
+	// ObjectID of the image
+	ObjectID string
+	Position WatermarkPosition
+}
+
+// Watermarks of TranscoderCreateRequest, encoded as object of slot names and image object IDs
+type Watermarks []Watermark
+
+// Validate checks fields of watermark
+func (w *Watermark) Validate() error {
+	if !idSegment.MatchString(w.ObjectID) {
+		return fmt.Errorf("filespot: bad watermark object ID %q", w.ObjectID)
+	}
+
+	return w.validatePosition()
+}
+
+// Validate checks watermarks fit slots of preset, a slot may be used once
+func (ws Watermarks) Validate(preset *Preset) error {
+	seen := make(map[WatermarkPosition]bool)
+
+	for i := range ws {
+		w := &ws[i]
+
+		err := w.Validate()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := preset.Watermarks[string(w.Position)]; !ok {
+			return fmt.Errorf("filespot: preset %q has no watermark slot %v", preset.Name, w.Position)
+		}
+
+		if seen[w.Position] {
+			return fmt.Errorf("filespot: watermark slot %v is used twice", w.Position)
+		}
+		seen[w.Position] = true
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes watermarks as object of slots
+func (ws Watermarks) MarshalJSON() ([]byte, error) {
+	if ws == nil {
+		return []byte("null"), nil
+	}
+
+	m := make(map[string]string, len(ws))
+	for _, w := range ws {
+		m[string(w.Position)] = w.ObjectID
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes watermarks ordered by position
+func (ws *Watermarks) UnmarshalJSON(data []byte) error {
+	var m map[string]string
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	if m == nil {
+		*ws = nil
+		return nil
+	}
+
+	watermarks := make(Watermarks, 0, len(m))
+	for position, id := range m {
+		watermarks = append(watermarks, Watermark{ObjectID: id, Position: WatermarkPosition(position)})
+	}
+
+	sort.Slice(watermarks, func(i, j int) bool {
+		return watermarks[i].Position < watermarks[j].Position
+	})
+	*ws = watermarks
+
+	return nil
+}
+
+// ValidateWatermarks checks watermarks of request fit every preset of request
+func (r *TranscoderCreateRequest) ValidateWatermarks(ctx context.Context, catalog *PresetCatalog) error {
+	if len(r.Watermarks) == 0 {
+		return nil
+	}
+
+	for _, id := range r.Presets {
+		preset, err := catalog.ByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = r.Watermarks.Validate(preset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UploadWatermark uploads local PNG image via ObjectsService and adds watermark
+// with ID of the uploaded object to request, nothing is uploaded when position is invalid
+func (r *TranscoderCreateRequest) UploadWatermark(ctx context.Context, objects ObjectsService, path string, position WatermarkPosition) (*Object, error) {
+	w := Watermark{Position: position}
+	err := w.validatePosition()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range r.Watermarks {
+		if existing.Position == position {
+			return nil, fmt.Errorf("filespot: watermark slot %v is used twice", position)
+		}
+	}
+
+	err = checkPNG(path)
+	if err != nil {
+		return nil, err
+	}
+
+	object, _, err := objects.Create(ctx, &ObjectCreateRequest{File: path, Name: filepath.Base(path)})
+	if err != nil {
+		return nil, err
+	}
+
+	w.ObjectID = object.ID
+	r.Watermarks = append(r.Watermarks, w)
+
+	return object, nil
+}
+
+// validatePosition checks watermark has a slot
+func (w *Watermark) validatePosition() error {
+	if w.Position == "" {
+		return errors.New("filespot: watermark position is missing")
+	}
+
+	return nil
+}
+
+// checkPNG checks file exists and starts with PNG signature
+func checkPNG(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	signature := make([]byte, len(pngSignature))
+	_, err = io.ReadFull(file, signature)
+	if err != nil || !bytes.Equal(signature, pngSignature) {
+		return ErrNotPNG
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package filespot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWatermarksJSON(t *testing.T) {
+	watermarks := Watermarks{
+		{ObjectID: "5adfa939534b446a607d9937", Position: WatermarkBottomLeft},
+		{ObjectID: "5adfa939534b446a607d9938", Position: WatermarkFull},
+	}
+
+	data, err := json.Marshal(watermarks)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	expected := `{"BottomLeft":"5adfa939534b446a607d9937","Full":"5adfa939534b446a607d9938"}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal = %s, expected %s", data, expected)
+	}
+
+	var decoded Watermarks
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, watermarks) {
+		t.Errorf("json.Unmarshal = %+v, expected %+v", decoded, watermarks)
+	}
+
+	data, _ = json.Marshal(&TranscoderCreateRequest{})
+	if string(data) != `{"presets":null,"path":"","watermarks":null,"del_original":false,"start":0,"duration":0}` {
+		t.Errorf("json.Marshal of empty request = %s", data)
+	}
+}
+
+func TestWatermarksValidate(t *testing.T) {
+	preset := &Preset{
+		Name: "Generic 1080p",
+		Watermarks: map[string]WatermarkSlot{
+			"BottomLeft": {HorizontalAlign: "Left"},
+			"Full":       {HorizontalAlign: "Left"},
+		},
+	}
+
+	id := "5adfa939534b446a607d9937"
+	cases := []struct {
+		watermarks Watermarks
+		valid      bool
+	}{
+		{Watermarks{{ObjectID: id, Position: WatermarkFull}, {ObjectID: id, Position: WatermarkBottomLeft}}, true},
+		{Watermarks{{ObjectID: id, Position: WatermarkTopRight}}, false},
+		{Watermarks{{ObjectID: id, Position: WatermarkFull}, {ObjectID: id, Position: WatermarkFull}}, false},
+		{Watermarks{{ObjectID: "logo.png", Position: WatermarkFull}}, false},
+		{Watermarks{{ObjectID: id}}, false},
+	}
+
+	for i, c := range cases {
+		err := c.watermarks.Validate(preset)
+		if (err == nil) != c.valid {
+			t.Errorf("case %d: Watermarks.Validate = %v, expected valid %v", i, err, c.valid)
+		}
+	}
+}
+
+func TestTranscoderCreateRequest_ValidateWatermarks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/1/transcoder/presets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+            "code": 200,
+            "presets": [
+                {"id": "566b0fbf044dfe64f2000002", "name": "Generic 1080p", "watermarks": {"Full": {"opacity": "100"}}},
+                {"id": "566b0fbf044dfe64f2000003", "name": "Generic 720p", "watermarks": null}
+            ]
+        }`)
+	})
+
+	catalog := NewPresetCatalog(client.Transcoder)
+	transcoderCreateRequest := &TranscoderCreateRequest{
+		Presets:    []string{"566b0fbf044dfe64f2000002"},
+		Watermarks: Watermarks{{ObjectID: "5adfa939534b446a607d9937", Position: WatermarkFull}},
+	}
+
+	err := transcoderCreateRequest.ValidateWatermarks(ctx, catalog)
+	if err != nil {
+		t.Errorf("TranscoderCreateRequest.ValidateWatermarks returned error: %v", err)
+	}
+
+	transcoderCreateRequest.Presets = append(transcoderCreateRequest.Presets, "566b0fbf044dfe64f2000003")
+	err = transcoderCreateRequest.ValidateWatermarks(ctx, catalog)
+	if err == nil {
+		t.Errorf("TranscoderCreateRequest.ValidateWatermarks expected error of preset without slots")
+	}
+}
+
+func TestTranscoderCreateRequest_UploadWatermark(t *testing.T) {
+	setup()
+	defer teardown()
+
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	os.WriteFile(logo, append([]byte("\x89PNG\r\n\x1a\n"), "image"...), 0o644)
+
+	uploads := 0
+	mux.HandleFunc("/1/objects", func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Objects.Create request has no file: %v", err)
+			return
+		}
+		defer file.Close()
+
+		data, _ := io.ReadAll(file)
+		if header.Filename != "logo.png" || string(data) != "\x89PNG\r\n\x1a\nimage" {
+			t.Errorf("Objects.Create file = %v %q, expected %v", header.Filename, data, "logo.png")
+		}
+
+		if name := r.FormValue("name"); name != "logo.png" {
+			t.Errorf("Objects.Create name = %v, expected %v", name, "logo.png")
+		}
+
+		fmt.Fprint(w, `{"code": 200, "status": "success", "object": {"id": "5adfa939534b446a607d9937", "name": "logo.png"}}`)
+	})
+
+	transcoderCreateRequest := &TranscoderCreateRequest{Presets: []string{"566b0fbf044dfe64f2000002"}}
+	object, err := transcoderCreateRequest.UploadWatermark(ctx, client.Objects, logo, WatermarkBottomRight)
+	if err != nil {
+		t.Fatalf("TranscoderCreateRequest.UploadWatermark returned error: %v", err)
+	}
+
+	expected := Watermarks{{ObjectID: "5adfa939534b446a607d9937", Position: WatermarkBottomRight}}
+	if object.ID != "5adfa939534b446a607d9937" || !reflect.DeepEqual(transcoderCreateRequest.Watermarks, expected) {
+		t.Errorf("TranscoderCreateRequest.Watermarks = %+v, expected %+v", transcoderCreateRequest.Watermarks, expected)
+	}
+
+	text := filepath.Join(dir, "logo.txt")
+	os.WriteFile(text, []byte("not an image"), 0o644)
+
+	_, err = transcoderCreateRequest.UploadWatermark(ctx, client.Objects, text, WatermarkFull)
+	if err != ErrNotPNG {
+		t.Errorf("TranscoderCreateRequest.UploadWatermark error = %v, expected %v", err, ErrNotPNG)
+	}
+
+	for _, position := range []WatermarkPosition{"", WatermarkBottomRight} {
+		_, err = transcoderCreateRequest.UploadWatermark(ctx, client.Objects, logo, position)
+		if err == nil {
+			t.Errorf("TranscoderCreateRequest.UploadWatermark expected error of position %q", position)
+		}
+	}
+
+	if uploads != 1 {
+		t.Errorf("Objects.Create calls = %v, expected %v", uploads, 1)
+	}
+}